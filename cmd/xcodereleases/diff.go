@@ -0,0 +1,94 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/go-darwin/tools/pkg/xcoderelease"
+)
+
+func init() {
+	register(&command{name: "diff", args: "<old-source> [new-source]", short: "Compare two snapshots of the releases", run: runDiff})
+}
+
+// diffView is the JSON and YAML form of a xcoderelease.DiffResult.
+type diffView struct {
+	Added   []*xcoderelease.XcodeRelease `json:"added"`
+	Removed []*xcoderelease.XcodeRelease `json:"removed"`
+	Changed []changeView                 `json:"changed"`
+}
+
+type changeView struct {
+	Build    string      `json:"build"`
+	Old      string      `json:"old"`
+	New      string      `json:"new"`
+	Promoted bool        `json:"promoted"`
+	Fields   []fieldView `json:"fields"`
+}
+
+type fieldView struct {
+	Path string `json:"path"`
+	Old  string `json:"old"`
+	New  string `json:"new"`
+}
+
+func newDiffView(d *xcoderelease.DiffResult) *diffView {
+	v := &diffView{
+		Added:   append([]*xcoderelease.XcodeRelease{}, d.Added...),
+		Removed: append([]*xcoderelease.XcodeRelease{}, d.Removed...),
+		Changed: []changeView{},
+	}
+	for _, c := range d.Changed {
+		cv := changeView{
+			Build:    c.Build,
+			Old:      c.Old.Title(),
+			New:      c.New.Title(),
+			Promoted: c.Promoted(),
+			Fields:   []fieldView{},
+		}
+		for _, f := range c.Fields {
+			cv.Fields = append(cv.Fields, fieldView{Path: f.Path, Old: f.Old, New: f.New})
+		}
+		v.Changed = append(v.Changed, cv)
+	}
+
+	return v
+}
+
+func runDiff(ctx context.Context, e *env, args []string) error {
+	if err := e.parse(args); err != nil {
+		return err
+	}
+
+	newSource := e.opts.source
+	switch len(e.args) {
+	case 1:
+	case 2:
+		newSource = e.args[1]
+	default:
+		return errUsage
+	}
+
+	old, err := e.opts.releases(ctx, e.args[0])
+	if err != nil {
+		return fmt.Errorf("old: %w", err)
+	}
+	newer, err := e.opts.releases(ctx, newSource)
+	if err != nil {
+		return fmt.Errorf("new: %w", err)
+	}
+
+	d, err := xcoderelease.Diff(old, newer)
+	if err != nil {
+		return err
+	}
+
+	return e.opts.write(e.stdout, newDiffView(d), func(tw *tabwriter.Writer) {
+		io.WriteString(tw, d.String())
+	})
+}
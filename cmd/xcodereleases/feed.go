@@ -0,0 +1,49 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"context"
+
+	"github.com/go-darwin/tools/pkg/xcoderelease"
+)
+
+func init() {
+	register(&command{name: "feed", short: "Generate an Atom or RSS feed of the releases", run: runFeed})
+}
+
+func runFeed(ctx context.Context, e *env, args []string) error {
+	var (
+		feed     xcoderelease.Feed
+		channels channelsFlag
+	)
+	e.fs.Var(&channels, "channel", "comma separated release channels: release, beta, dp, gmseed, gm or rc")
+	e.fs.StringVar(&feed.Title, "title", "", "feed title (default \""+xcoderelease.DefaultFeedTitle+"\")")
+	e.fs.StringVar(&feed.Self, "self", "", "URL the feed is served at")
+	e.fs.IntVar(&feed.Limit, "n", 50, "list at most n releases (0 lists all)")
+
+	format := e.fs.Lookup(fnameFormat)
+	format.Usage = "feed format: atom or rss"
+	format.DefValue = string(xcoderelease.FeedAtom)
+	format.Value.Set(format.DefValue)
+
+	if err := e.parse(args); err != nil {
+		return err
+	}
+	if len(e.args) > 0 {
+		return errUsage
+	}
+	feed.Channels = channels
+
+	ff, err := xcoderelease.ParseFeedFormat(e.opts.format)
+	if err != nil {
+		return err
+	}
+	xrs, err := e.opts.releases(ctx, e.opts.source)
+	if err != nil {
+		return err
+	}
+
+	return feed.Encode(e.stdout, ff, xrs)
+}
@@ -0,0 +1,77 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/go-darwin/tools/pkg/xcoderelease"
+)
+
+func init() {
+	register(&command{name: "installed", args: "[Xcode.app ...]", short: "Identify the installed Xcode bundles", run: runInstalled})
+}
+
+// defaultInstallGlob matches the Xcode bundles installed in the usual location.
+const defaultInstallGlob = "/Applications/Xcode*.app"
+
+// installedView is the JSON and YAML form of an identified installation.
+type installedView struct {
+	*xcoderelease.Installation
+	Release *xcoderelease.XcodeRelease `json:"release"`
+}
+
+func runInstalled(ctx context.Context, e *env, args []string) error {
+	if err := e.parse(args); err != nil {
+		return err
+	}
+
+	paths := e.args
+	if len(paths) == 0 {
+		var err error
+		if paths, err = filepath.Glob(defaultInstallGlob); err != nil {
+			return err
+		}
+		if len(paths) == 0 {
+			return fmt.Errorf("no Xcode found in %s", filepath.Dir(defaultInstallGlob))
+		}
+	}
+
+	ix, err := e.opts.index(ctx)
+	if err != nil {
+		return err
+	}
+
+	views := []installedView{}
+	for _, path := range paths {
+		xr, inst, err := ix.FindInstalled(path)
+		var rerr *xcoderelease.ResolveError
+		if err != nil && !errors.As(err, &rerr) {
+			return err
+		}
+		views = append(views, installedView{Installation: inst, Release: xr})
+	}
+
+	return e.opts.write(e.stdout, views, func(tw *tabwriter.Writer) {
+		fmt.Fprintln(tw, "PATH\tVERSION\tBUILD\tRELEASE\tSDKS")
+		for _, v := range views {
+			release := "unknown"
+			if v.Release != nil {
+				release = v.Release.Title()
+			}
+			var sdks []string
+			for _, s := range v.SDKs {
+				if !s.Simulator {
+					sdks = append(sdks, strings.TrimSpace(string(s.Platform)+" "+s.Version))
+				}
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", v.Path, orDash(v.Version), v.Build, release, orDash(strings.Join(sdks, ", ")))
+		}
+	})
+}
@@ -0,0 +1,159 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/go-darwin/tools/pkg/xcoderelease"
+)
+
+func init() {
+	register(&command{name: "list", short: "List the releases, newest first", run: runList})
+	register(&command{name: "latest", short: "Show the latest release", run: runLatest})
+}
+
+const dateLayout = "2006-01-02"
+
+// channelsFlag is a comma separated list of the release channels.
+type channelsFlag []xcoderelease.Channel
+
+func (f *channelsFlag) String() string {
+	ss := make([]string, len(*f))
+	for i, ch := range *f {
+		ss[i] = ch.String()
+	}
+
+	return strings.Join(ss, ",")
+}
+
+func (f *channelsFlag) Set(s string) error {
+	for _, name := range strings.Split(s, ",") {
+		ch, err := xcoderelease.ParseChannel(name)
+		if err != nil {
+			return err
+		}
+		*f = append(*f, ch)
+	}
+
+	return nil
+}
+
+// dateFlag is a date in the YYYY-MM-DD form.
+type dateFlag struct{ time.Time }
+
+func (f *dateFlag) String() string {
+	if f.IsZero() {
+		return ""
+	}
+
+	return f.Format(dateLayout)
+}
+
+func (f *dateFlag) Set(s string) error {
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return fmt.Errorf("invalid date %q, want YYYY-MM-DD", s)
+	}
+	f.Time = t
+
+	return nil
+}
+
+func runList(ctx context.Context, e *env, args []string) error {
+	var (
+		channels     channelsFlag
+		since, until dateFlag
+	)
+	e.fs.Var(&channels, "channel", "comma separated release channels: release, beta, dp, gmseed, gm or rc")
+	platform := e.fs.String("platform", "", "only releases bundling the SDK of the platform, e.g. iOS")
+	sdk := e.fs.String("sdk", "", "only releases bundling the SDK version of -platform, e.g. 15 or 15.2")
+	e.fs.Var(&since, "since", "only releases dated on or after the date (YYYY-MM-DD)")
+	e.fs.Var(&until, "until", "only releases dated on or before the date (YYYY-MM-DD)")
+	limit := e.fs.Int("n", 0, "list at most n releases (0 lists all)")
+	if err := e.parse(args); err != nil {
+		return err
+	}
+	if len(e.args) > 0 || (*sdk != "" && *platform == "") {
+		return errUsage
+	}
+
+	ix, err := e.opts.index(ctx)
+	if err != nil {
+		return err
+	}
+
+	q := ix.Query().DateRange(since.Time, until.Time)
+	if len(channels) > 0 {
+		q = q.Channel(channels...)
+	}
+	if *platform != "" {
+		q = q.HasSDK(xcoderelease.ParsePlatform(*platform), *sdk)
+	}
+
+	xrs := q.All()
+	if *limit > 0 && len(xrs) > *limit {
+		xrs = xrs[:*limit]
+	}
+
+	return e.opts.writeReleases(e.stdout, xrs, func(tw *tabwriter.Writer) {
+		writeReleaseRows(tw, xrs)
+	})
+}
+
+func runLatest(ctx context.Context, e *env, args []string) error {
+	channel := xcoderelease.ChannelRelease
+	e.fs.Func("channel", "release channel: release, beta, dp, gmseed, gm or rc (default release)", func(s string) (err error) {
+		channel, err = xcoderelease.ParseChannel(s)
+		return err
+	})
+	if err := e.parse(args); err != nil {
+		return err
+	}
+	if len(e.args) > 0 {
+		return errUsage
+	}
+
+	ix, err := e.opts.index(ctx)
+	if err != nil {
+		return err
+	}
+
+	xr := ix.Latest(channel)
+	if xr == nil {
+		return fmt.Errorf("no release in the %s channel", channel)
+	}
+
+	return e.opts.writeReleases(e.stdout, []*xcoderelease.XcodeRelease{xr}, func(tw *tabwriter.Writer) {
+		writeReleaseRows(tw, []*xcoderelease.XcodeRelease{xr})
+	})
+}
+
+// writeReleaseRows writes a row per release to tw.
+func writeReleaseRows(tw *tabwriter.Writer, xrs []*xcoderelease.XcodeRelease) {
+	fmt.Fprintln(tw, "VERSION\tBUILD\tDATE\tREQUIRES\tMACOS SDK\tIOS SDK\tSWIFT")
+	for _, xr := range xrs {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			xr.Title(),
+			xr.Version.Build,
+			xr.Date,
+			orDash(xr.Requires),
+			orDash(xr.SDKNumbers(xcoderelease.PlatformMacOS)),
+			orDash(xr.SDKNumbers(xcoderelease.PlatformIOS)),
+			orDash(xr.CompilerNumbers(xcoderelease.CompilerSwift)),
+		)
+	}
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+
+	return s
+}
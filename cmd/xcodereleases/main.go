@@ -0,0 +1,159 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+// Command xcodereleases queries the Xcode releases listed on xcodereleases.com.
+//
+// Usage:
+//
+//	xcodereleases <command> [flags] [args]
+//
+// The commands are:
+//
+//	list        list releases
+//	show        show a release by version, build or specifier
+//	latest      show the latest release
+//	sdks        list the SDKs bundled with releases
+//	compilers   list the compilers bundled with releases
+//	diff        compare two snapshots of the releases
+//	stats       report the timeline statistics of the releases
+//	validate    report the suspicious entries of the releases
+//	installed   identify the installed Xcode bundles
+//	feed        generate an Atom or RSS feed of the releases
+//	matrix      generate a CI build matrix of the releases
+//	serve       serve the releases over HTTP
+//
+// Every command accepts the -source flag, which is the URL or file path of data.json,
+// and the -format flag, which is one of table, json or yaml. The list command also accepts csv and markdown, the matrix
+// command accepts github and buildkite, and the feed command accepts atom and rss.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+)
+
+const (
+	exitSuccess = iota
+	exitFailure
+	exitUsage
+)
+
+// command is a subcommand of xcodereleases.
+type command struct {
+	name  string
+	args  string
+	short string
+	run   func(ctx context.Context, env *env, args []string) error
+}
+
+// commands is the subcommands keyed by the name. Each command registers itself in init.
+var commands = make(map[string]*command)
+
+func register(c *command) {
+	commands[c.name] = c
+}
+
+// env is the execution environment of a command.
+type env struct {
+	stdout io.Writer
+	stderr io.Writer
+	fs     *flag.FlagSet
+	opts   *options
+	args   []string // positional arguments left by parse
+}
+
+// parse parses the flags in args, which may be interspersed with the positional arguments.
+// The positional arguments are left in e.args.
+func (e *env) parse(args []string) error {
+	e.args = nil
+	for {
+		if err := e.fs.Parse(args); err != nil {
+			return err
+		}
+		rest := e.fs.Args()
+		if len(rest) == 0 {
+			return nil
+		}
+		if n := len(args) - len(rest); n > 0 && args[n-1] == "--" {
+			e.args = append(e.args, rest...)
+			return nil
+		}
+		e.args = append(e.args, rest[0])
+		args = rest[1:]
+	}
+}
+
+// errUsage is returned by the commands on an invalid usage.
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	os.Exit(run(ctx, os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(stderr)
+		if len(args) == 0 {
+			return exitUsage
+		}
+		return exitSuccess
+	}
+
+	c, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "xcodereleases: unknown command %q\n", args[0])
+		usage(stderr)
+		return exitUsage
+	}
+
+	e := &env{
+		stdout: stdout,
+		stderr: stderr,
+		fs:     flag.NewFlagSet(c.name, flag.ContinueOnError),
+		opts:   new(options),
+	}
+	e.fs.SetOutput(stderr)
+	e.fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: xcodereleases %s [flags] %s\n\n%s.\n\nFlags:\n", c.name, c.args, c.short)
+		e.fs.PrintDefaults()
+	}
+	e.opts.register(e.fs)
+
+	if err := c.run(ctx, e, args[1:]); err != nil {
+		switch {
+		case errors.Is(err, flag.ErrHelp):
+			return exitSuccess
+		case errors.Is(err, errUsage):
+			e.fs.Usage()
+			return exitUsage
+		}
+		fmt.Fprintf(stderr, "xcodereleases %s: %v\n", c.name, err)
+		return exitFailure
+	}
+
+	return exitSuccess
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "usage: xcodereleases <command> [flags] [args]\n\nCommands:\n")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-12s %s\n", name, commands[name].short)
+	}
+
+	fmt.Fprintf(w, "\nRun 'xcodereleases <command> -h' for the command flags.\n")
+}
@@ -0,0 +1,82 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/go-darwin/tools/pkg/xcoderelease"
+)
+
+func init() {
+	register(&command{name: "matrix", short: "Generate a CI build matrix of the releases", run: runMatrix})
+}
+
+// specsFlag is a repeatable release specifier.
+type specsFlag []string
+
+func (f *specsFlag) String() string { return strings.Join(*f, ",") }
+
+func (f *specsFlag) Set(s string) error {
+	*f = append(*f, s)
+	return nil
+}
+
+func runMatrix(ctx context.Context, e *env, args []string) error {
+	var (
+		policy  xcoderelease.MatrixPolicy
+		include specsFlag
+	)
+	e.fs.IntVar(&policy.Majors, "majors", 2, "select the latest release of each of the newest n major versions")
+	e.fs.BoolVar(&policy.Prerelease, "beta", false, "also select the newest prerelease if it is newer than all releases")
+	e.fs.Func("host", "only select the releases which run on the macOS version, e.g. 11.6", func(s string) (err error) {
+		policy.Host, err = xcoderelease.ParseOSVersion(s)
+		return err
+	})
+	e.fs.Var(&include, "include", "also select the release of the version, build or specifier (repeatable)")
+	command := e.fs.String("command", "", "command of the Buildkite steps, required by the buildkite format")
+	e.fs.Lookup(fnameFormat).Usage = "output format: table, json, yaml, github or buildkite"
+	if err := e.parse(args); err != nil {
+		return err
+	}
+	if len(e.args) > 0 {
+		return errUsage
+	}
+	policy.Include = include
+
+	f, _ := xcoderelease.ParseMatrixFormat(e.opts.format)
+	if f == xcoderelease.MatrixBuildkite && *command == "" {
+		return fmt.Errorf("the buildkite format needs -command")
+	}
+
+	ix, err := e.opts.index(ctx)
+	if err != nil {
+		return err
+	}
+	entries, err := ix.Matrix(policy)
+	if err != nil {
+		return err
+	}
+
+	switch f {
+	case xcoderelease.MatrixGitHub:
+		return xcoderelease.EncodeGitHubMatrix(e.stdout, entries)
+	case xcoderelease.MatrixBuildkite:
+		return xcoderelease.EncodeBuildkiteSteps(e.stdout, entries, *command)
+	}
+
+	if entries == nil {
+		entries = []xcoderelease.MatrixEntry{}
+	}
+	return e.opts.write(e.stdout, entries, func(tw *tabwriter.Writer) {
+		fmt.Fprintln(tw, "XCODE\tVERSION\tBUILD\tMACOS SDK\tIOS SDK\tTVOS SDK\tWATCHOS SDK")
+		for _, m := range entries {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				m.Xcode, m.Version, m.Build, orDash(m.MacOSSDK), orDash(m.IOSSDK), orDash(m.TvOSSDK), orDash(m.WatchOSSDK))
+		}
+	})
+}
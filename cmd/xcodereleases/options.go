@@ -0,0 +1,121 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	json "github.com/goccy/go-json"
+
+	"github.com/go-darwin/tools/pkg/xcoderelease"
+)
+
+const (
+	fnameSource   = "source"
+	fnameFormat   = "format"
+	fnameCacheAge = "cache-max-age"
+)
+
+const (
+	defaultSource = "https://xcodereleases.com/data.json"
+	formatTable   = "table"
+)
+
+// options is the flags common to all commands.
+type options struct {
+	source   string
+	format   string
+	cacheAge time.Duration
+}
+
+func (o *options) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.source, fnameSource, defaultSource, `URL or file path of data.json`)
+	fs.StringVar(&o.format, fnameFormat, formatTable, "output format: table, json or yaml")
+	fs.DurationVar(&o.cacheAge, fnameCacheAge, 0, "cache the downloaded data.json for the duration (0 disables the cache)")
+}
+
+// client returns the Client which reads from source.
+func (o *options) client(source string) (*xcoderelease.Client, error) {
+	c, err := xcoderelease.NewClient(source)
+	if err != nil {
+		return nil, err
+	}
+	c.UserAgent = "xcodereleases (+https://github.com/go-darwin/tools)"
+	c.Retry = xcoderelease.DefaultRetryPolicy
+	if o.cacheAge > 0 {
+		c.Cache = &xcoderelease.Cache{MaxAge: o.cacheAge}
+	}
+
+	return c, nil
+}
+
+// releases loads the releases from source.
+func (o *options) releases(ctx context.Context, source string) ([]*xcoderelease.XcodeRelease, error) {
+	c, err := o.client(source)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Releases(ctx)
+}
+
+// index loads the releases from the -source flag and indexes them.
+func (o *options) index(ctx context.Context) (*xcoderelease.Index, error) {
+	xrs, err := o.releases(ctx, o.source)
+	if err != nil {
+		return nil, err
+	}
+
+	return xcoderelease.NewIndex(xrs), nil
+}
+
+// writeReleases writes xrs in the -format, using table for the table format.
+func (o *options) writeReleases(w io.Writer, xrs []*xcoderelease.XcodeRelease, table func(tw *tabwriter.Writer)) error {
+	switch strings.ToLower(o.format) {
+	case "csv", "markdown", "md":
+		f, err := xcoderelease.ParseFormat(o.format)
+		if err != nil {
+			return err
+		}
+		return xcoderelease.Encode(w, f, xrs)
+	}
+
+	if xrs == nil {
+		xrs = []*xcoderelease.XcodeRelease{}
+	}
+	return o.write(w, xrs, table)
+}
+
+// write writes v in the -format, using table for the table format.
+func (o *options) write(w io.Writer, v interface{}, table func(tw *tabwriter.Writer)) error {
+	switch strings.ToLower(o.format) {
+	case formatTable:
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		table(tw)
+		return tw.Flush()
+
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+
+	case "yaml", "yml":
+		data, err := xcoderelease.MarshalYAML(v)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+
+	default:
+		return fmt.Errorf("unsupported format %q", o.format)
+	}
+}
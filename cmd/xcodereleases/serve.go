@@ -0,0 +1,95 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/go-darwin/tools/pkg/xcoderelease"
+)
+
+func init() {
+	register(&command{name: "serve", short: "Serve the releases over HTTP", run: runServe})
+}
+
+func runServe(ctx context.Context, e *env, args []string) error {
+	addr := e.fs.String("addr", "localhost:8080", "listen address")
+	refresh := e.fs.Duration("refresh", 0, "reload the -source at the interval (0 loads it once)")
+	if err := e.parse(args); err != nil {
+		return err
+	}
+	if len(e.args) > 0 {
+		return errUsage
+	}
+
+	c, err := e.opts.client(e.opts.source)
+	if err != nil {
+		return err
+	}
+	data, err := c.DownloadJSON(ctx)
+	if err != nil {
+		return err
+	}
+	s, err := xcoderelease.NewServer(data)
+	if err != nil {
+		return err
+	}
+
+	logger := log.New(e.stderr, "xcodereleases serve: ", log.LstdFlags)
+	if *refresh > 0 {
+		go reload(ctx, logger, c, s, *refresh)
+	}
+
+	ln, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+		ErrorLog:          logger,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	logger.Printf("serving %s on http://%s", e.opts.source, ln.Addr())
+	if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+// reload updates s with the data.json downloaded by c every interval until ctx is done.
+// The errors are logged and s keeps serving the previous data.
+func reload(ctx context.Context, logger *log.Logger, c *xcoderelease.Client, s *xcoderelease.Server, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+
+		data, err := c.DownloadJSON(ctx)
+		if err == nil {
+			err = s.Update(data)
+		}
+		if err != nil && ctx.Err() == nil {
+			logger.Print(fmt.Errorf("reload: %w", err))
+		}
+	}
+}
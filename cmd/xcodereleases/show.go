@@ -0,0 +1,226 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/go-darwin/tools/pkg/xcoderelease"
+)
+
+func init() {
+	register(&command{name: "show", args: "<version|build>", short: "Show a release by version, build or specifier", run: runShow})
+	register(&command{name: "sdks", args: "[version|build]", short: "List the SDKs bundled with the releases", run: runSDKs})
+	register(&command{name: "compilers", args: "[version|build]", short: "List the compilers bundled with the releases", run: runCompilers})
+}
+
+// resolve returns the releases selected by args, which is a specifier accepted by Index.Resolve
+// possibly split into several arguments such as "13.2 beta 2". No args selects all releases.
+func resolve(ix *xcoderelease.Index, args []string) ([]*xcoderelease.XcodeRelease, error) {
+	if len(args) == 0 {
+		return ix.Releases(), nil
+	}
+
+	xr, err := ix.Resolve(strings.Join(args, " "))
+	if err != nil {
+		return nil, err
+	}
+
+	return []*xcoderelease.XcodeRelease{xr}, nil
+}
+
+func runShow(ctx context.Context, e *env, args []string) error {
+	if err := e.parse(args); err != nil {
+		return err
+	}
+	if len(e.args) == 0 {
+		return errUsage
+	}
+
+	ix, err := e.opts.index(ctx)
+	if err != nil {
+		return err
+	}
+	xrs, err := resolve(ix, e.args)
+	if err != nil {
+		return err
+	}
+	xr := xrs[0]
+
+	// show prints a single release, so the JSON and YAML are the object rather than a list.
+	return e.opts.write(e.stdout, xr, func(tw *tabwriter.Writer) {
+		fmt.Fprintf(tw, "Name:\t%s\n", xr.Name)
+		fmt.Fprintf(tw, "Version:\t%s\n", xr.Title())
+		fmt.Fprintf(tw, "Build:\t%s\n", xr.Version.Build)
+		fmt.Fprintf(tw, "Date:\t%s\n", xr.Date)
+		fmt.Fprintf(tw, "Requires:\tmacOS %s\n", orDash(xr.Requires))
+		for _, p := range xr.SDKs.Platforms() {
+			fmt.Fprintf(tw, "%s SDK:\t%s\n", p, sdkDetails(xr.SDKs.For(p)))
+		}
+		if xr.Compilers != nil {
+			for _, c := range compilersOf(xr) {
+				fmt.Fprintf(tw, "%s:\t%s\n", c.kind, c.details())
+			}
+		}
+		fmt.Fprintf(tw, "SHA-1:\t%s\n", orDash(xr.Checksums.Sha1))
+		fmt.Fprintf(tw, "Download:\t%s\n", orDash(xr.Links.Download.URL))
+		fmt.Fprintf(tw, "Notes:\t%s\n", orDash(xr.Links.Notes.URL))
+	})
+}
+
+func sdkDetails(sdks []xcoderelease.SDK) string {
+	ss := make([]string, len(sdks))
+	for i, s := range sdks {
+		ss[i] = withBuild(s.Number, s.Build)
+	}
+
+	return strings.Join(ss, ", ")
+}
+
+func withBuild(number, build string) string {
+	if build == "" {
+		return number
+	}
+
+	return fmt.Sprintf("%s (%s)", number, build)
+}
+
+// sdkRow is a row of the sdks command.
+type sdkRow struct {
+	Xcode    string `json:"xcode"`
+	XcodeBld string `json:"xcodeBuild"`
+	Platform string `json:"platform"`
+	Number   string `json:"number"`
+	Build    string `json:"build"`
+}
+
+func runSDKs(ctx context.Context, e *env, args []string) error {
+	platform := e.fs.String("platform", "", "only the SDKs of the platform, e.g. iOS")
+	if err := e.parse(args); err != nil {
+		return err
+	}
+
+	ix, err := e.opts.index(ctx)
+	if err != nil {
+		return err
+	}
+	xrs, err := resolve(ix, e.args)
+	if err != nil {
+		return err
+	}
+
+	rows := []sdkRow{}
+	for _, xr := range xrs {
+		for _, p := range xr.SDKs.Platforms() {
+			if *platform != "" && p != xcoderelease.ParsePlatform(*platform) {
+				continue
+			}
+			for _, s := range xr.SDKs.For(p) {
+				rows = append(rows, sdkRow{
+					Xcode:    xr.Title(),
+					XcodeBld: xr.Version.Build,
+					Platform: string(p),
+					Number:   s.Number,
+					Build:    s.Build,
+				})
+			}
+		}
+	}
+
+	return e.opts.write(e.stdout, rows, func(tw *tabwriter.Writer) {
+		fmt.Fprintln(tw, "XCODE\tXCODE BUILD\tPLATFORM\tSDK\tSDK BUILD")
+		for _, r := range rows {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", r.Xcode, r.XcodeBld, r.Platform, r.Number, orDash(r.Build))
+		}
+	})
+}
+
+// compiler is a compiler bundled with a release.
+type compiler struct {
+	kind   xcoderelease.Compiler
+	number string
+	build  string
+}
+
+func (c compiler) details() string {
+	return withBuild(c.number, c.build)
+}
+
+// compilersOf returns the compilers bundled with xr in the order of the data.json.
+func compilersOf(xr *xcoderelease.XcodeRelease) []compiler {
+	cs := xr.Compilers
+	if cs == nil {
+		return nil
+	}
+
+	var out []compiler
+	for _, c := range cs.Clang {
+		out = append(out, compiler{xcoderelease.CompilerClang, c.Number, c.Build})
+	}
+	for _, c := range cs.Gcc {
+		out = append(out, compiler{xcoderelease.CompilerGCC, c.Number, c.Build})
+	}
+	for _, c := range cs.Llvm {
+		out = append(out, compiler{xcoderelease.CompilerLLVM, c.Number, ""})
+	}
+	for _, c := range cs.LlvmGcc {
+		out = append(out, compiler{xcoderelease.CompilerLLVMGCC, c.Number, c.Build})
+	}
+	for _, c := range cs.Swift {
+		out = append(out, compiler{xcoderelease.CompilerSwift, c.Number, c.Build})
+	}
+
+	return out
+}
+
+// compilerRow is a row of the compilers command.
+type compilerRow struct {
+	Xcode    string `json:"xcode"`
+	XcodeBld string `json:"xcodeBuild"`
+	Compiler string `json:"compiler"`
+	Number   string `json:"number"`
+	Build    string `json:"build,omitempty"`
+}
+
+func runCompilers(ctx context.Context, e *env, args []string) error {
+	kind := e.fs.String("compiler", "", "only the compiler: clang, gcc, llvm, llvm_gcc or swift")
+	if err := e.parse(args); err != nil {
+		return err
+	}
+
+	ix, err := e.opts.index(ctx)
+	if err != nil {
+		return err
+	}
+	xrs, err := resolve(ix, e.args)
+	if err != nil {
+		return err
+	}
+
+	rows := []compilerRow{}
+	for _, xr := range xrs {
+		for _, c := range compilersOf(xr) {
+			if *kind != "" && !strings.EqualFold(string(c.kind), *kind) {
+				continue
+			}
+			rows = append(rows, compilerRow{
+				Xcode:    xr.Title(),
+				XcodeBld: xr.Version.Build,
+				Compiler: string(c.kind),
+				Number:   c.number,
+				Build:    c.build,
+			})
+		}
+	}
+
+	return e.opts.write(e.stdout, rows, func(tw *tabwriter.Writer) {
+		fmt.Fprintln(tw, "XCODE\tXCODE BUILD\tCOMPILER\tVERSION\tBUILD")
+		for _, r := range rows {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", r.Xcode, r.XcodeBld, r.Compiler, r.Number, orDash(r.Build))
+		}
+	})
+}
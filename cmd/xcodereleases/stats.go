@@ -0,0 +1,37 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-darwin/tools/pkg/xcoderelease"
+)
+
+func init() {
+	register(&command{name: "stats", short: "Report the timeline statistics of the releases", run: runStats})
+}
+
+func runStats(ctx context.Context, e *env, args []string) error {
+	if err := e.parse(args); err != nil {
+		return err
+	}
+	if len(e.args) > 0 {
+		return errUsage
+	}
+
+	xrs, err := e.opts.releases(ctx, e.opts.source)
+	if err != nil {
+		return err
+	}
+	stats := xcoderelease.ComputeStats(xrs)
+
+	// The report aligns its own tables.
+	if strings.EqualFold(e.opts.format, formatTable) {
+		return stats.WriteReport(e.stdout)
+	}
+
+	return e.opts.write(e.stdout, stats, nil)
+}
@@ -0,0 +1,60 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/go-darwin/tools/pkg/xcoderelease"
+)
+
+func init() {
+	register(&command{name: "validate", short: "Report the suspicious entries of the releases", run: runValidate})
+}
+
+func runValidate(ctx context.Context, e *env, args []string) error {
+	warnings := e.fs.Bool("strict", false, "fail on the warnings as well as the errors")
+	if err := e.parse(args); err != nil {
+		return err
+	}
+	if len(e.args) > 0 {
+		return errUsage
+	}
+
+	xrs, err := e.opts.releases(ctx, e.opts.source)
+	if err != nil {
+		return err
+	}
+
+	findings := xcoderelease.Validate(xrs)
+	if findings == nil {
+		findings = []xcoderelease.Finding{}
+	}
+	if err := e.opts.write(e.stdout, findings, func(tw *tabwriter.Writer) {
+		fmt.Fprintln(tw, "PATH\tSEVERITY\tRULE\tRELEASE\tMESSAGE")
+		for _, f := range findings {
+			release := "-"
+			if f.Release != nil {
+				release = f.Release.Version.Number + " (" + f.Release.Version.Build + ")"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", f.Path, f.Severity, f.Rule, release, f.Message)
+		}
+	}); err != nil {
+		return err
+	}
+
+	var failed int
+	for _, f := range findings {
+		if f.Severity == xcoderelease.SeverityError || *warnings {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d finding(s) failed", failed, len(findings))
+	}
+
+	return nil
+}
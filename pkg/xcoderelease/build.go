@@ -0,0 +1,230 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Build represents a parsed Apple build identifier such as "13C100" or "21A5248p".
+type Build struct {
+	// Major is the major train number, e.g. 21 of "21A5248p".
+	Major int
+
+	// Train is the train letter, e.g. 'A' of "21A5248p".
+	Train byte
+
+	// Number is the build number, e.g. 5248 of "21A5248p".
+	Number int
+
+	// Suffix is the optional trailing letter, e.g. 'p' of "21A5248p". It is zero if absent.
+	Suffix byte
+}
+
+// ParseBuild parses the Apple build identifier s.
+func ParseBuild(s string) (Build, error) {
+	var b Build
+
+	i := 0
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	if i == 0 || i == len(s) || !isUpper(s[i]) {
+		return Build{}, fmt.Errorf("invalid build %q", s)
+	}
+	b.Major, _ = strconv.Atoi(s[:i])
+	b.Train = s[i]
+	i++
+
+	j := i
+	for j < len(s) && isDigit(s[j]) {
+		j++
+	}
+	if j == i {
+		return Build{}, fmt.Errorf("invalid build %q", s)
+	}
+	var err error
+	if b.Number, err = strconv.Atoi(s[i:j]); err != nil {
+		return Build{}, fmt.Errorf("invalid build %q: %w", s, err)
+	}
+
+	switch {
+	case j == len(s):
+		// no suffix
+	case j == len(s)-1 && isLower(s[j]):
+		b.Suffix = s[j]
+	default:
+		return Build{}, fmt.Errorf("invalid build %q", s)
+	}
+
+	return b, nil
+}
+
+func isDigit(c byte) bool { return '0' <= c && c <= '9' }
+func isUpper(c byte) bool { return 'A' <= c && c <= 'Z' }
+func isLower(c byte) bool { return 'a' <= c && c <= 'z' }
+
+// String returns the build identifier of b, or an empty string if b is the zero Build.
+func (b Build) String() string {
+	switch {
+	case b == Build{}:
+		return ""
+	case b.Suffix == 0:
+		return fmt.Sprintf("%d%c%d", b.Major, b.Train, b.Number)
+	default:
+		return fmt.Sprintf("%d%c%d%c", b.Major, b.Train, b.Number, b.Suffix)
+	}
+}
+
+// IsSeed reports whether b has the trailing suffix letter which marks seed builds.
+//
+// Note that a few final releases such as Xcode 12.4 (12D4e) also carry a suffix.
+func (b Build) IsSeed() bool {
+	return b.Suffix != 0
+}
+
+// seedOffset is the build number from which Apple numbers the seed builds of a train. The seeds count up
+// from the offset in thousands, e.g. 13C5081f is a seed of 13C90, and 12A8189n is a seed of 12A7209.
+const seedOffset = 5000
+
+// base returns the build number without the seed offset, e.g. 81 of 13C5081f.
+func (b Build) base() int {
+	if b.Number >= seedOffset {
+		return b.Number % 1000
+	}
+
+	return b.Number
+}
+
+// Compare returns -1, 0 or +1 depending on whether b is older, the same or newer than c.
+//
+// Builds are ordered by major train number, train letter, the build number without the seed offset,
+// and then a build with the seed offset before the one without, so that 13C5081f < 13C90 < 13C100.
+// The ties are broken by the build number and then the suffix, where no suffix sorts before any suffix letter.
+func (b Build) Compare(c Build) int {
+	if r := compareInts(b.Major, c.Major); r != 0 {
+		return r
+	}
+	if r := compareInts(int(b.Train), int(c.Train)); r != 0 {
+		return r
+	}
+	if r := compareInts(b.base(), c.base()); r != 0 {
+		return r
+	}
+	if bs, cs := b.Number >= seedOffset, c.Number >= seedOffset; bs != cs {
+		if bs {
+			return -1
+		}
+		return +1
+	}
+	if r := compareInts(b.Number, c.Number); r != 0 {
+		return r
+	}
+
+	return compareInts(int(b.Suffix), int(c.Suffix))
+}
+
+// Less reports whether b is older than c.
+func (b Build) Less(c Build) bool {
+	return b.Compare(c) < 0
+}
+
+// ParseBuild parses the build identifier of the Xcode version.
+func (v Version) ParseBuild() (Build, error) { return ParseBuild(v.Build) }
+
+// ParseBuild parses the build identifier of the SDK.
+func (s SDK) ParseBuild() (Build, error) { return ParseBuild(s.Build) }
+
+// CompilerBuild represents a parsed compiler build identifier such as "clang-1300.0.29.30" or "swiftlang-1300.0.47.5".
+type CompilerBuild struct {
+	// Name is the optional project name, e.g. "clang" of "clang-1300.0.29.30".
+	Name string
+
+	// Numbers is the dotted numbers, e.g. [1300 0 29 30] of "clang-1300.0.29.30".
+	Numbers []int
+}
+
+// ParseCompilerBuild parses the compiler build identifier s, which is the dotted numbers
+// optionally prefixed by the project name and a hyphen.
+func ParseCompilerBuild(s string) (CompilerBuild, error) {
+	var b CompilerBuild
+
+	rest := s
+	if i := strings.LastIndexByte(s, '-'); i >= 0 {
+		b.Name, rest = s[:i], s[i+1:]
+		if b.Name == "" {
+			return CompilerBuild{}, fmt.Errorf("invalid compiler build %q", s)
+		}
+	}
+
+	for _, p := range strings.Split(rest, ".") {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 || !isDigit(p[0]) {
+			return CompilerBuild{}, fmt.Errorf("invalid compiler build %q", s)
+		}
+		b.Numbers = append(b.Numbers, n)
+	}
+
+	return b, nil
+}
+
+// String returns the build identifier of b.
+func (b CompilerBuild) String() string {
+	var sb strings.Builder
+
+	if b.Name != "" {
+		sb.WriteString(b.Name + "-")
+	}
+	for i, n := range b.Numbers {
+		if i > 0 {
+			sb.WriteByte('.')
+		}
+		sb.WriteString(strconv.Itoa(n))
+	}
+
+	return sb.String()
+}
+
+// Compare returns -1, 0 or +1 depending on whether b is older, the same or newer than c.
+//
+// Builds are ordered by the name and then by the numbers, where the missing trailing numbers are zero.
+func (b CompilerBuild) Compare(c CompilerBuild) int {
+	if r := strings.Compare(b.Name, c.Name); r != 0 {
+		return r
+	}
+
+	for i := 0; i < len(b.Numbers) || i < len(c.Numbers); i++ {
+		var x, y int
+		if i < len(b.Numbers) {
+			x = b.Numbers[i]
+		}
+		if i < len(c.Numbers) {
+			y = c.Numbers[i]
+		}
+		if r := compareInts(x, y); r != 0 {
+			return r
+		}
+	}
+
+	return 0
+}
+
+// Less reports whether b is older than c.
+func (b CompilerBuild) Less(c CompilerBuild) bool {
+	return b.Compare(c) < 0
+}
+
+// ParseBuild parses the build identifier of the compiler.
+func (c ClangCompiler) ParseBuild() (CompilerBuild, error) { return ParseCompilerBuild(c.Build) }
+
+// ParseBuild parses the build identifier of the compiler.
+func (c GCCCompiler) ParseBuild() (CompilerBuild, error) { return ParseCompilerBuild(c.Build) }
+
+// ParseBuild parses the build identifier of the compiler.
+func (c LLVMGCCCompiler) ParseBuild() (CompilerBuild, error) { return ParseCompilerBuild(c.Build) }
+
+// ParseBuild parses the build identifier of the compiler.
+func (c SwiftCompiler) ParseBuild() (CompilerBuild, error) { return ParseCompilerBuild(c.Build) }
@@ -0,0 +1,173 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseBuild(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Build
+		wantErr bool
+	}{
+		{in: "13C100", want: Build{Major: 13, Train: 'C', Number: 100}},
+		{in: "21A5248p", want: Build{Major: 21, Train: 'A', Number: 5248, Suffix: 'p'}},
+		{in: "12D4e", want: Build{Major: 12, Train: 'D', Number: 4, Suffix: 'e'}},
+		{in: "", wantErr: true},
+		{in: "13", wantErr: true},
+		{in: "C100", wantErr: true},
+		{in: "13c100", wantErr: true},
+		{in: "13C", wantErr: true},
+		{in: "13C100pp", wantErr: true},
+		{in: "13C100P", wantErr: true},
+		{in: "clang-1300.0.29.30", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseBuild(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseBuild(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseBuild(%q) = %#v, want %#v", tt.in, got, tt.want)
+		}
+		if !tt.wantErr && got.String() != tt.in {
+			t.Errorf("ParseBuild(%q).String() = %q", tt.in, got.String())
+		}
+	}
+}
+
+func TestBuildCompare(t *testing.T) {
+	// ordered from the oldest
+	ordered := []string{
+		"12A8158a", "12A8189h", "12A8189n", "12A7209", "12A7300",
+		"12D4e",
+		"13A5154h", "13A233", "13A1030d",
+		"13C5066c", "13C5081f", "13C90", "13C100", "13C100a", "13C100b",
+		"13E5086k",
+		"21A5248p",
+	}
+
+	for i := range ordered {
+		for j := range ordered {
+			bi, _ := ParseBuild(ordered[i])
+			bj, _ := ParseBuild(ordered[j])
+			want := compareInts(i, j)
+			if got := bi.Compare(bj); got != want {
+				t.Errorf("%q.Compare(%q) = %d, want %d", ordered[i], ordered[j], got, want)
+			}
+			if got := bi.Less(bj); got != (want < 0) {
+				t.Errorf("%q.Less(%q) = %v, want %v", ordered[i], ordered[j], got, want < 0)
+			}
+		}
+	}
+}
+
+func TestBuildCompareSeedAndFinal(t *testing.T) {
+	// the seed and the final builds of the same train, the seed first
+	tests := [][2]string{
+		{"13C5081f", "13C100"},
+		{"13C5081f", "13C90"},
+		{"13A5154h", "13A1030d"},
+		{"13A5154h", "13A233"},
+		{"12A8189n", "12A7209"},
+		{"13E5086k", "13E113"},
+	}
+	for _, tt := range tests {
+		seed, _ := ParseBuild(tt[0])
+		final, _ := ParseBuild(tt[1])
+		if !seed.Less(final) || final.Less(seed) {
+			t.Errorf("%s.Less(%s) = %v, want the seed first", tt[0], tt[1], seed.Less(final))
+		}
+	}
+}
+
+func TestBuildIsSeed(t *testing.T) {
+	for s, want := range map[string]bool{"13C100": false, "13C5081f": true} {
+		b, err := ParseBuild(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := b.IsSeed(); got != want {
+			t.Errorf("ParseBuild(%q).IsSeed() = %v, want %v", s, got, want)
+		}
+	}
+}
+
+func TestParseCompilerBuild(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    CompilerBuild
+		wantErr bool
+	}{
+		{in: "clang-1300.0.29.30", want: CompilerBuild{Name: "clang", Numbers: []int{1300, 0, 29, 30}}},
+		{in: "swiftlang-1300.0.47.5", want: CompilerBuild{Name: "swiftlang", Numbers: []int{1300, 0, 47, 5}}},
+		{in: "llvm-gcc-2336.11", want: CompilerBuild{Name: "llvm-gcc", Numbers: []int{2336, 11}}},
+		{in: "5666.3", want: CompilerBuild{Numbers: []int{5666, 3}}},
+		{in: "421", want: CompilerBuild{Numbers: []int{421}}},
+		{in: "", wantErr: true},
+		{in: "clang-", wantErr: true},
+		{in: "-1300", wantErr: true},
+		{in: "clang-1300..29", wantErr: true},
+		{in: "clang-1300.0.+29", wantErr: true},
+		{in: "13C100", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseCompilerBuild(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseCompilerBuild(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseCompilerBuild(%q) = %#v, want %#v", tt.in, got, tt.want)
+		}
+		if !tt.wantErr && got.String() != tt.in {
+			t.Errorf("ParseCompilerBuild(%q).String() = %q", tt.in, got.String())
+		}
+	}
+}
+
+func TestCompilerBuildCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"clang-1300.0.29.30", "clang-1300.0.29.30", 0},
+		{"clang-1300.0.29.3", "clang-1300.0.29.30", -1},
+		{"clang-1300.0.29.30", "clang-1205.0.22.11", +1},
+		{"clang-1300", "clang-1300.0", 0},
+		{"clang-1300", "clang-1300.0.1", -1},
+		{"5666.3", "clang-1", -1},
+	}
+	for _, tt := range tests {
+		a, err := ParseCompilerBuild(tt.a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := ParseCompilerBuild(tt.b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := a.Compare(b); got != tt.want {
+			t.Errorf("%q.Compare(%q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := b.Compare(a); got != -tt.want {
+			t.Errorf("%q.Compare(%q) = %d, want %d", tt.b, tt.a, got, -tt.want)
+		}
+	}
+}
+
+func TestCompilerParseBuild(t *testing.T) {
+	c := ClangCompiler{Build: "clang-1300.0.29.30"}
+	b, err := c.ParseBuild()
+	if err != nil {
+		t.Fatalf("ClangCompiler.ParseBuild() error = %v", err)
+	}
+	if b.String() != c.Build {
+		t.Errorf("ClangCompiler.ParseBuild() = %v, want %v", b, c.Build)
+	}
+}
@@ -0,0 +1,224 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	json "github.com/goccy/go-json"
+)
+
+// Cache is an on-disk cache of data.json.
+//
+// The cached payload is stored together with its ETag and Last-Modified values,
+// and revalidated with a conditional request once it is older than MaxAge.
+// If the revalidation fails with a network error or a temporary 429 or 5xx response, e.g. when offline,
+// the cached payload is used as is.
+type Cache struct {
+	// Dir is the cache directory. If empty, DefaultCacheDir is used.
+	Dir string
+
+	// MaxAge is the duration a cached payload is used without revalidation.
+	// Zero revalidates on every use.
+	MaxAge time.Duration
+}
+
+// DefaultCacheDir returns the default cache directory under the user cache directory.
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "go-darwin", "xcoderelease"), nil
+}
+
+// cacheMeta is the metadata of a cached payload.
+type cacheMeta struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"lastModified,omitempty"`
+	FetchedAt    time.Time `json:"fetchedAt"`
+}
+
+func (c *Cache) dir() (string, error) {
+	if c.Dir != "" {
+		return c.Dir, nil
+	}
+
+	return DefaultCacheDir()
+}
+
+// paths returns the payload and metadata file paths of u.
+func (c *Cache) paths(u *url.URL) (data, meta string, err error) {
+	dir, err := c.dir()
+	if err != nil {
+		return "", "", err
+	}
+
+	sum := sha256.Sum256([]byte(u.String()))
+	key := hex.EncodeToString(sum[:8])
+
+	return filepath.Join(dir, key+".json"), filepath.Join(dir, key+".meta.json"), nil
+}
+
+// load loads the cached payload of u. It returns nil meta if u is not cached.
+func (c *Cache) load(u *url.URL) (*cacheMeta, []byte, error) {
+	dataPath, metaPath, err := c.paths(u)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	buf, err := os.ReadFile(metaPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	meta := new(cacheMeta)
+	if err := json.Unmarshal(buf, meta); err != nil || meta.URL != u.String() {
+		return nil, nil, nil // treat a corrupted entry as missing
+	}
+
+	data, err := os.ReadFile(dataPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+
+	return meta, data, nil
+}
+
+// store stores the payload of u. A nil data only updates the metadata.
+func (c *Cache) store(u *url.URL, meta *cacheMeta, data []byte) error {
+	dataPath, metaPath, err := c.paths(u)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dataPath), 0o755); err != nil {
+		return err
+	}
+
+	if data != nil {
+		if err := writeFileAtomic(dataPath, data); err != nil {
+			return err
+		}
+	}
+
+	buf, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(metaPath, buf)
+}
+
+// writeFileAtomic writes data to a temporary file and renames it to name.
+func writeFileAtomic(name string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), name)
+}
+
+// downloadCached downloads u through cache.
+//
+// The cached copy is returned if the download fails with a temporary error or runs out of c.Timeout,
+// unless ctx itself is done.
+func (c *Client) downloadCached(ctx context.Context, u *url.URL, cache *Cache) ([]byte, error) {
+	meta, cached, err := cache.load(u)
+	if err != nil {
+		return nil, fmt.Errorf("load cache: %w", err)
+	}
+	if meta != nil && cache.MaxAge > 0 && time.Since(meta.FetchedAt) < cache.MaxAge {
+		return cached, nil
+	}
+
+	hdr := make(http.Header)
+	if meta != nil {
+		if meta.ETag != "" {
+			hdr.Set("If-None-Match", meta.ETag)
+		}
+		if meta.LastModified != "" {
+			hdr.Set("If-Modified-Since", meta.LastModified)
+		}
+	}
+
+	reqCtx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	var (
+		data        []byte
+		notModified bool
+		newMeta     = &cacheMeta{URL: u.String()}
+	)
+	err = c.Retry.retry(reqCtx, func() error {
+		resp, err := c.get(reqCtx, u, hdr)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		newMeta.ETag = resp.Header.Get("ETag")
+		newMeta.LastModified = resp.Header.Get("Last-Modified")
+		newMeta.FetchedAt = time.Now()
+		if resp.StatusCode == http.StatusNotModified {
+			if meta == nil {
+				// The conditional request came from Client.Header, and there is no copy to reuse.
+				return newHTTPError(resp)
+			}
+			notModified = true
+			return nil
+		}
+
+		data, err = io.ReadAll(resp.Body)
+		return err
+	})
+	if err != nil {
+		if meta != nil && ctx.Err() == nil && (retryable(err) || reqCtx.Err() != nil) {
+			return cached, nil // offline or temporary upstream failure, use the stale copy
+		}
+		return nil, err
+	}
+
+	if notModified {
+		if newMeta.ETag == "" {
+			newMeta.ETag = meta.ETag
+		}
+		if newMeta.LastModified == "" {
+			newMeta.LastModified = meta.LastModified
+		}
+		_ = cache.store(u, newMeta, nil) // best effort
+		return cached, nil
+	}
+
+	_ = cache.store(u, newMeta, data) // best effort
+
+	return data, nil
+}
@@ -0,0 +1,140 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDownloadCached(t *testing.T) {
+	const body = `[]`
+
+	tests := []struct {
+		name string
+		// status is the status code of the second response. The first one is 200 with body.
+		status     int
+		wantStatus int // status of *HTTPError, or 0 for the cached body
+	}{
+		{name: "NotModified", status: http.StatusNotModified},
+		{name: "ServiceUnavailable", status: http.StatusServiceUnavailable},
+		{name: "TooManyRequests", status: http.StatusTooManyRequests},
+		{name: "NotFound", status: http.StatusNotFound, wantStatus: http.StatusNotFound},
+		{name: "Forbidden", status: http.StatusForbidden, wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var n int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&n, 1) == 1 {
+					w.Header().Set("ETag", `"v1"`)
+					w.Write([]byte(body))
+					return
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			c := &Client{URL: mustParseURL(t, srv.URL), Cache: &Cache{Dir: t.TempDir()}}
+			if _, err := c.DownloadJSON(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := c.DownloadJSON(context.Background())
+			if tt.wantStatus == 0 {
+				if err != nil {
+					t.Fatalf("DownloadJSON() error = %v, want the cached copy", err)
+				}
+				if string(data) != body {
+					t.Fatalf("DownloadJSON() = %q, want %q", data, body)
+				}
+				return
+			}
+
+			var herr *HTTPError
+			if !errors.As(err, &herr) || herr.StatusCode != tt.wantStatus {
+				t.Fatalf("DownloadJSON() error = %v, want HTTPError %d", err, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestDownloadCachedTimeout(t *testing.T) {
+	const body = `[]`
+
+	var n int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&n, 1) == 1 {
+			w.Write([]byte(body))
+			return
+		}
+		select { // hang until the client gives up
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	c := &Client{URL: mustParseURL(t, srv.URL), Cache: &Cache{Dir: t.TempDir()}}
+	if _, err := c.DownloadJSON(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	c.Timeout = 50 * time.Millisecond
+	data, err := c.DownloadJSON(context.Background())
+	if err != nil {
+		t.Fatalf("DownloadJSON() error = %v, want the cached copy after the timeout", err)
+	}
+	if string(data) != body {
+		t.Fatalf("DownloadJSON() = %q, want %q", data, body)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	c.Timeout = time.Minute
+	if _, err := c.DownloadJSON(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("DownloadJSON(done ctx) error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestDownloadNotModifiedWithoutCopy(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	defer srv.Close()
+
+	for _, cache := range []*Cache{nil, {Dir: t.TempDir()}} {
+		c := &Client{
+			URL:    mustParseURL(t, srv.URL),
+			Header: http.Header{"If-None-Match": {`"v1"`}},
+			Cache:  cache,
+		}
+		data, err := c.DownloadJSON(context.Background())
+
+		var herr *HTTPError
+		if !errors.As(err, &herr) || herr.StatusCode != http.StatusNotModified {
+			t.Errorf("DownloadJSON(cache %v) = %q, %v, want HTTPError 304", cache != nil, data, err)
+		}
+	}
+}
+
+func mustParseURL(t *testing.T, s string) *url.URL {
+	t.Helper()
+
+	u, err := url.Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return u
+}
@@ -0,0 +1,239 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	json "github.com/goccy/go-json"
+)
+
+// Source selects where Client reads the release data from.
+type Source int
+
+const (
+	// SourceLive downloads data.json from the Client URL, revalidating the Client Cache if set.
+	SourceLive Source = iota
+
+	// SourceCached uses the cached data.json regardless of its age, and downloads it only if not cached yet.
+	// The Client Cache is used if set, otherwise a Cache in DefaultCacheDir.
+	SourceCached
+)
+
+// String implements fmt.Stringer.
+func (s Source) String() string {
+	switch s {
+	case SourceLive:
+		return "live"
+	case SourceCached:
+		return "cached"
+	default:
+		return fmt.Sprintf("Source(%d)", int(s))
+	}
+}
+
+// Client fetches and parses the xcodereleases data.json.
+//
+// The zero value is usable and fetches https://xcodereleases.com/data.json through http.DefaultClient.
+type Client struct {
+	// URL is the location of data.json. The http, https and file schemes are supported.
+	// If nil, the xcodereleases.com URL is used.
+	URL *url.URL
+
+	// HTTPClient is the HTTP client used for the http and https schemes.
+	// If nil, http.DefaultClient is used.
+	HTTPClient *http.Client
+
+	// Header is the additional header sent with each request.
+	Header http.Header
+
+	// UserAgent overrides the User-Agent header if not empty.
+	UserAgent string
+
+	// Timeout limits the time spent by a single DownloadJSON call, including retries. Zero means no timeout.
+	Timeout time.Duration
+
+	// Retry is the retry policy of failed requests. If nil, requests are not retried.
+	Retry *RetryPolicy
+
+	// Cache is the on-disk cache of the http and https downloads. If nil, nothing is cached.
+	Cache *Cache
+
+	// Source selects where the release data is read from. The default is SourceLive.
+	Source Source
+
+	// Mode selects how Unmarshal treats the data which does not follow the known schema.
+	Mode DecodeMode
+}
+
+// DefaultClient is the default Client used by DownloadJSON and Unmarshal.
+var DefaultClient = &Client{}
+
+// NewClient returns a new Client which fetches data.json from source.
+//
+// The source is either an absolute URL or a local file path.
+func NewClient(source string) (*Client, error) {
+	u, err := parseSource(source)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{URL: u}, nil
+}
+
+// parseSource parses source as an URL, falling back to a file URL if source has no scheme.
+func parseSource(source string) (*url.URL, error) {
+	u, err := url.Parse(source)
+	if err == nil && u.Scheme != "" && len(u.Scheme) > 1 { // len(u.Scheme) == 1 is the Windows drive letter
+		return u, nil
+	}
+
+	path, err := filepath.Abs(source)
+	if err != nil {
+		return nil, fmt.Errorf("parse source %q: %w", source, err)
+	}
+
+	return &url.URL{Scheme: "file", Path: filepath.ToSlash(path)}, nil
+}
+
+func (c *Client) url() *url.URL {
+	if c.URL != nil {
+		return c.URL
+	}
+
+	return dataURI
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+
+	return http.DefaultClient
+}
+
+// DownloadJSON downloads xcodereleases data.json.
+func (c *Client) DownloadJSON(ctx context.Context) ([]byte, error) {
+	u := c.url()
+	switch u.Scheme {
+	case "file":
+		return os.ReadFile(filepath.FromSlash(u.Path))
+	case "http", "https":
+		// nothing to do
+	default:
+		return nil, fmt.Errorf("unsupported URL scheme: %q", u.Scheme)
+	}
+
+	switch {
+	case c.Source == SourceCached:
+		cache := Cache{MaxAge: math.MaxInt64}
+		if c.Cache != nil {
+			cache.Dir = c.Cache.Dir
+		}
+		return c.downloadCached(ctx, u, &cache)
+	case c.Cache != nil:
+		return c.downloadCached(ctx, u, c.Cache)
+	}
+
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	var data []byte
+	err := c.Retry.retry(ctx, func() error {
+		resp, err := c.get(ctx, u, nil)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusNotModified {
+			// The conditional request came from Header, and there is no cached copy to reuse.
+			return newHTTPError(resp)
+		}
+
+		data, err = io.ReadAll(resp.Body)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// withTimeout returns ctx limited by c.Timeout.
+func (c *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.Timeout > 0 {
+		return context.WithTimeout(ctx, c.Timeout)
+	}
+
+	return context.WithCancel(ctx)
+}
+
+// get sends a GET request to u with the additional hdr and returns the response if it has a 2xx or 304 status code.
+// Otherwise it returns *HTTPError.
+func (c *Client) get(ctx context.Context, u *url.URL, hdr http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range []http.Header{c.Header, hdr} {
+		for k, vs := range h {
+			for _, v := range vs {
+				req.Header.Add(k, v)
+			}
+		}
+	}
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if (resp.StatusCode < 200 || resp.StatusCode > 299) && resp.StatusCode != http.StatusNotModified {
+		defer resp.Body.Close()
+		return nil, newHTTPError(resp)
+	}
+
+	return resp, nil
+}
+
+// Unmarshal parses the xcodereleases.com JSON-encoded data and returns the new XcodeRelease.
+func (c *Client) Unmarshal(data []byte) ([]*XcodeRelease, error) {
+	switch c.Mode {
+	case DecodeStrict:
+		return UnmarshalStrict(data)
+	case DecodeLenient:
+		return UnmarshalLenient(data)
+	default:
+		return unmarshal(data)
+	}
+}
+
+func unmarshal(data []byte) (xrs []*XcodeRelease, err error) {
+	if err := json.UnmarshalNoEscape(data, &xrs); err != nil {
+		return nil, fmt.Errorf("unmarshal data: %w", err)
+	}
+
+	return xrs, nil
+}
+
+// Releases downloads and parses xcodereleases data.json.
+func (c *Client) Releases(ctx context.Context) ([]*XcodeRelease, error) {
+	data, err := c.DownloadJSON(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Unmarshal(data)
+}
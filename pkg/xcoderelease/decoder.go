@@ -0,0 +1,165 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	json "github.com/goccy/go-json"
+)
+
+// Decoder reads the releases one at a time from the data.json stream.
+type Decoder struct {
+	r       *eofReader
+	dec     *json.Decoder
+	started bool
+	err     error
+}
+
+// NewDecoder returns the new Decoder that reads from r.
+//
+// The Decoder reads only as much of r as needed to decode the next release.
+func NewDecoder(r io.Reader) *Decoder {
+	er := &eofReader{r: r}
+	return &Decoder{r: er, dec: json.NewDecoder(er)}
+}
+
+// eofReader counts the bytes read from r and records whether r reached io.EOF.
+type eofReader struct {
+	r   io.Reader
+	n   int64
+	eof bool
+}
+
+func (r *eofReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	if err == io.EOF {
+		r.eof = true
+	}
+
+	return n, err
+}
+
+// Next decodes the next release from the stream.
+//
+// It returns io.EOF when the stream ends, and keeps returning the same error after any error.
+func (d *Decoder) Next() (*XcodeRelease, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	xr, err := d.next()
+	if err != nil {
+		d.err = err
+		return nil, err
+	}
+
+	return xr, nil
+}
+
+func (d *Decoder) next() (*XcodeRelease, error) {
+	if !d.started {
+		tok, err := d.dec.Token()
+		if err != nil {
+			return nil, d.decodeError(err)
+		}
+		if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+			return nil, fmt.Errorf("decode data: expected array, got %v", tok)
+		}
+		d.started = true
+	}
+
+	if !d.dec.More() {
+		if _, err := d.dec.Token(); err != nil { // consume ']'
+			return nil, d.decodeError(err)
+		}
+		return nil, io.EOF
+	}
+
+	xr := new(XcodeRelease)
+	if err := d.dec.Decode(xr); err != nil {
+		return nil, d.decodeError(err)
+	}
+
+	return xr, nil
+}
+
+// decodeError wraps err, which is io.ErrUnexpectedEOF if the stream ended in the middle of the data.
+//
+// The json.Decoder reports a truncated value as a syntax error, either at the end of the stream or
+// as an unexpected end of JSON input.
+func (d *Decoder) decodeError(err error) error {
+	if err == io.EOF || d.truncated(err) {
+		err = io.ErrUnexpectedEOF
+	}
+
+	return fmt.Errorf("decode data: %w", err)
+}
+
+func (d *Decoder) truncated(err error) bool {
+	var serr *json.SyntaxError
+	if !d.r.eof || !errors.As(err, &serr) {
+		return false
+	}
+
+	return serr.Offset >= d.r.n || strings.HasSuffix(serr.Error(), "unexpected end of JSON input")
+}
+
+// Open opens the xcodereleases data.json for streaming with Decoder. The caller must close the returned reader.
+//
+// The http and https responses are streamed directly unless the Client has a Cache or uses SourceCached,
+// in which case the payload is read through the cache first.
+func (c *Client) Open(ctx context.Context) (io.ReadCloser, error) {
+	u := c.url()
+	switch {
+	case u.Scheme == "file":
+		return os.Open(filepath.FromSlash(u.Path))
+	case c.Source == SourceCached, c.Cache != nil, u.Scheme != "http" && u.Scheme != "https":
+		data, err := c.DownloadJSON(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+
+	cancel := context.CancelFunc(func() {})
+	if c.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+	}
+
+	var body io.ReadCloser
+	err := c.Retry.retry(ctx, func() error {
+		resp, err := c.get(ctx, u, nil)
+		if err != nil {
+			return err
+		}
+		body = resp.Body
+		return nil
+	})
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	return &cancelReadCloser{ReadCloser: body, cancel: cancel}, nil
+}
+
+// cancelReadCloser cancels the context of the request on Close.
+type cancelReadCloser struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (r *cancelReadCloser) Close() error {
+	defer r.cancel()
+	return r.ReadCloser.Close()
+}
@@ -0,0 +1,183 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testDecoderJSON returns data.json of a few releases shaped like the upstream entries.
+func testDecoderJSON(t *testing.T) []byte {
+	t.Helper()
+
+	xrs := testReleases(t, "13.3 Beta 1 (13E5086k)", "13.2.1 (13C100)", "13.2 (13C90)", "13.2 RC (13C90)")
+	for i, xr := range xrs {
+		xr.Requires = "11.3"
+		xr.Date = Date{Day: 17 - i, Month: 12, Year: 2021}
+		xr.SDKs = &SDKs{MacOS: []SDK{{Build: "21C46", Number: "12.1", Release: true}}}
+		xr.Compilers = &Compilers{Swift: []SwiftCompiler{{Build: "swiftlang-1300.0.47.5", Number: "5.5.2", Release: true}}}
+	}
+	data, err := Marshal(xrs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+func decodeAll(d *Decoder) ([]*XcodeRelease, error) {
+	var xrs []*XcodeRelease
+	for {
+		xr, err := d.Next()
+		if err == io.EOF {
+			return xrs, nil
+		}
+		if err != nil {
+			return xrs, err
+		}
+		xrs = append(xrs, xr)
+	}
+}
+
+func TestDecoderMatchesUnmarshal(t *testing.T) {
+	data := testDecoderJSON(t)
+
+	want, err := Unmarshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := decodeAll(NewDecoder(bytes.NewReader(data)))
+	if err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Decoder = %v, want %v", got, want)
+	}
+
+	for _, data := range []string{`[]`, ` [ ] `} {
+		got, err := decodeAll(NewDecoder(strings.NewReader(data)))
+		if err != nil || len(got) != 0 {
+			t.Errorf("Decoder(%q) = %v, %v, want nothing", data, got, err)
+		}
+	}
+}
+
+func TestDecoderStopsEarly(t *testing.T) {
+	data := testDecoderJSON(t)
+	first := bytes.Index(data, []byte(`},{`)) + len(`},`)
+
+	// The rest of the stream is written only after the first release is decoded.
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		pw.Write(data[:first])
+		<-done
+		pw.Write(data[first:])
+		pw.Close()
+	}()
+
+	d := NewDecoder(pr)
+	type result struct {
+		xr  *XcodeRelease
+		err error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		xr, err := d.Next()
+		ch <- result{xr, err}
+	}()
+
+	select {
+	case r := <-ch:
+		if r.err != nil || r.xr.Version.Build != "13E5086k" {
+			t.Fatalf("Next() = %v, %v, want 13E5086k", r.xr, r.err)
+		}
+	case <-time.After(5 * time.Second):
+		close(done)
+		t.Fatal("Next() blocked on the rest of the stream")
+	}
+
+	close(done)
+	rest, err := decodeAll(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := builds(rest); !reflect.DeepEqual(got, []string{"13C100", "13C90", "13C90"}) {
+		t.Errorf("the rest = %v", got)
+	}
+}
+
+func TestDecoderTruncated(t *testing.T) {
+	data := testDecoderJSON(t)
+
+	for n := 0; n < len(data); n++ {
+		d := NewDecoder(bytes.NewReader(data[:n]))
+		_, err := decodeAll(d)
+		if !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Fatalf("Decoder(data[:%d]) error = %v, want %v", n, err, io.ErrUnexpectedEOF)
+		}
+		if _, again := d.Next(); again != err {
+			t.Fatalf("Next() after the error = %v, want the same %v", again, err)
+		}
+	}
+
+	// a syntax error before the end is not a truncation
+	_, err := decodeAll(NewDecoder(strings.NewReader(`[{"name": x}]`)))
+	if err == nil || errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Decoder(syntax error) error = %v", err)
+	}
+}
+
+func TestDecoderNotArray(t *testing.T) {
+	for _, data := range []string{`{"name": "Xcode"}`, `"Xcode"`, `null`} {
+		_, err := NewDecoder(strings.NewReader(data)).Next()
+		if err == nil || err == io.EOF {
+			t.Errorf("Next(%s) error = %v, want an error", data, err)
+		}
+	}
+}
+
+func TestClientOpen(t *testing.T) {
+	data := testDecoderJSON(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(data)
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "data.json")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fc, err := NewClient(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []*Client{{URL: mustParseURL(t, srv.URL)}, fc} {
+		rc, err := c.Open(context.Background())
+		if err != nil {
+			t.Fatalf("Open(%s) error = %v", c.url(), err)
+		}
+		xrs, err := decodeAll(NewDecoder(rc))
+		rc.Close()
+		if err != nil {
+			t.Fatalf("Open(%s) decode error = %v", c.url(), err)
+		}
+		if len(xrs) != 4 {
+			t.Errorf("Open(%s) decoded %d releases, want 4", c.url(), len(xrs))
+		}
+	}
+}
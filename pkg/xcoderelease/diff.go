@@ -0,0 +1,262 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	json "github.com/goccy/go-json"
+)
+
+// DiffResult is the difference between two snapshots of the releases.
+type DiffResult struct {
+	// Added is the releases only in the new snapshot, in the new snapshot order.
+	Added []*XcodeRelease
+
+	// Removed is the releases only in the old snapshot, in the old snapshot order.
+	Removed []*XcodeRelease
+
+	// Changed is the releases changed between the snapshots, in the new snapshot order.
+	Changed []*ReleaseChange
+}
+
+// ReleaseChange is the field-level change of a release.
+type ReleaseChange struct {
+	// Build is the build number which identifies the release.
+	Build string
+
+	Old *XcodeRelease
+	New *XcodeRelease
+
+	// Fields is the changed fields ordered by path.
+	Fields []FieldChange
+}
+
+// FieldChange is a change of a field.
+type FieldChange struct {
+	// Path is the location of the field, e.g. "version.release.beta" or "sdks.macOS[0].number".
+	Path string
+
+	// Old and New are the JSON encoded values of the field, or empty if the field is absent.
+	Old string
+	New string
+}
+
+// Empty reports whether the snapshots are the same.
+func (d *DiffResult) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// Promoted reports whether the release moved to a later channel, e.g. from a beta or RC to the final release.
+func (c *ReleaseChange) Promoted() bool {
+	return c.Old.Version.Channel().rank() < c.New.Version.Channel().rank()
+}
+
+// Field returns the change of the field at path, or nil if the field is not changed.
+func (c *ReleaseChange) Field(path string) *FieldChange {
+	for i := range c.Fields {
+		if c.Fields[i].Path == path {
+			return &c.Fields[i]
+		}
+	}
+
+	return nil
+}
+
+// String returns the summary of d, a line for each added (+), removed (-) and changed (~) release.
+func (d *DiffResult) String() string {
+	var sb strings.Builder
+
+	for _, xr := range d.Added {
+		fmt.Fprintf(&sb, "+ %s\n", releaseTitle(xr))
+	}
+	for _, xr := range d.Removed {
+		fmt.Fprintf(&sb, "- %s\n", releaseTitle(xr))
+	}
+	for _, c := range d.Changed {
+		fmt.Fprintf(&sb, "~ %s\n", releaseTitle(c.New))
+		for _, f := range c.Fields {
+			fmt.Fprintf(&sb, "    %s: %s -> %s\n", f.Path, orNone(f.Old), orNone(f.New))
+		}
+	}
+
+	return sb.String()
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+
+	return s
+}
+
+// releaseTitle returns the human readable version of xr with the build, e.g. "13.2 Beta 2 (13C5081f)".
+func releaseTitle(xr *XcodeRelease) string {
+	v, err := xr.Version.XcodeVersion()
+	if err != nil {
+		return fmt.Sprintf("%s (%s)", xr.Version.Number, xr.Version.Build)
+	}
+
+	return v.String()
+}
+
+// Diff reports the releases added to, removed from and changed between the old and new snapshots.
+//
+// The releases are identified by the build number. If several releases share a build, such as a RC and
+// the final release built from it, they are paired by the release channel first and then in order.
+// A new release left unpaired whose build is shared with an old prerelease of an earlier channel, such as
+// a final release added next to its RC, is reported as a change from that prerelease, so Promoted reports true.
+func Diff(old, newer []*XcodeRelease) (*DiffResult, error) {
+	d := &DiffResult{}
+
+	olds := make(map[string][]*XcodeRelease)
+	for _, xr := range old {
+		olds[xr.Version.Build] = append(olds[xr.Version.Build], xr)
+	}
+
+	pairs := make(map[*XcodeRelease]*XcodeRelease) // new to old
+	news := make(map[string][]*XcodeRelease)
+	for _, xr := range newer {
+		news[xr.Version.Build] = append(news[xr.Version.Build], xr)
+	}
+	for build, nxrs := range news {
+		oxrs := append([]*XcodeRelease(nil), olds[build]...)
+		var rest []*XcodeRelease
+		for _, nxr := range nxrs {
+			if i := indexChannel(oxrs, nxr.Version.Channel()); i >= 0 {
+				pairs[nxr] = oxrs[i]
+				oxrs = append(oxrs[:i], oxrs[i+1:]...)
+				continue
+			}
+			rest = append(rest, nxr)
+		}
+		for _, nxr := range rest {
+			if len(oxrs) == 0 {
+				break
+			}
+			pairs[nxr] = oxrs[0]
+			oxrs = oxrs[1:]
+		}
+	}
+
+	paired := make(map[*XcodeRelease]bool)
+	for _, nxr := range newer {
+		oxr, ok := pairs[nxr]
+		if !ok {
+			oxr = promotedFrom(olds[nxr.Version.Build], nxr)
+		}
+		if oxr == nil {
+			d.Added = append(d.Added, nxr)
+			continue
+		}
+		paired[oxr] = true
+
+		fields, err := diffFields(oxr, nxr)
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) > 0 {
+			d.Changed = append(d.Changed, &ReleaseChange{Build: nxr.Version.Build, Old: oxr, New: nxr, Fields: fields})
+		}
+	}
+	for _, oxr := range old {
+		if !paired[oxr] {
+			d.Removed = append(d.Removed, oxr)
+		}
+	}
+
+	return d, nil
+}
+
+func indexChannel(xrs []*XcodeRelease, ch Channel) int {
+	for i, xr := range xrs {
+		if xr.Version.Channel() == ch {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// promotedFrom returns the release of the latest channel among xrs which is earlier than the channel of xr,
+// or nil if there is none.
+func promotedFrom(xrs []*XcodeRelease, xr *XcodeRelease) *XcodeRelease {
+	var from *XcodeRelease
+	for _, oxr := range xrs {
+		rank := oxr.Version.Channel().rank()
+		if rank < xr.Version.Channel().rank() && (from == nil || rank > from.Version.Channel().rank()) {
+			from = oxr
+		}
+	}
+
+	return from
+}
+
+// diffFields returns the changed fields between a and b.
+func diffFields(a, b *XcodeRelease) ([]FieldChange, error) {
+	fa, err := flatten(a)
+	if err != nil {
+		return nil, err
+	}
+	fb, err := flatten(b)
+	if err != nil {
+		return nil, err
+	}
+
+	var fs []FieldChange
+	for path, va := range fa {
+		if vb := fb[path]; va != vb {
+			fs = append(fs, FieldChange{Path: path, Old: va, New: vb})
+		}
+	}
+	for path, vb := range fb {
+		if _, ok := fa[path]; !ok {
+			fs = append(fs, FieldChange{Path: path, New: vb})
+		}
+	}
+	sort.Slice(fs, func(i, j int) bool { return fs[i].Path < fs[j].Path })
+
+	return fs, nil
+}
+
+// flatten returns the JSON encoded scalar values of xr keyed by the path.
+func flatten(xr *XcodeRelease) (map[string]string, error) {
+	data, err := marshalJSON(xr)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("flatten %s: %w", xr.Version.Build, err)
+	}
+
+	m := make(map[string]string)
+	flattenValue(m, "", v)
+
+	return m, nil
+}
+
+func flattenValue(m map[string]string, path string, v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			p := k
+			if path != "" {
+				p = path + "." + k
+			}
+			flattenValue(m, p, e)
+		}
+	case []interface{}:
+		for i, e := range v {
+			flattenValue(m, path+"["+strconv.Itoa(i)+"]", e)
+		}
+	default:
+		data, _ := marshalJSON(v)
+		m[path] = string(data)
+	}
+}
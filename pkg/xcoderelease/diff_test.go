@@ -0,0 +1,131 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	old := testReleases(t,
+		"13.2 RC (13C90)",
+		"13.2 Beta 2 (13C5066c)",
+		"13.1 (13A1030d)",
+		"13.0 (13A233)",
+	)
+	old[2].Checksums.Sha1 = "0123456789abcdef0123456789abcdef01234567"
+
+	next := testReleases(t,
+		"13.2.1 (13C100)",
+		"13.2 (13C90)",
+		"13.2 RC (13C90)",
+		"13.1 (13A1030d)",
+		"13.0 (13A233)",
+	)
+	next[3].Checksums.Sha1 = "76543210fedcba9876543210fedcba9876543210"
+	next[3].Requires = "11.3"
+
+	d, err := Diff(old, next)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := builds(d.Added); !reflect.DeepEqual(got, []string{"13C100"}) {
+		t.Errorf("Added = %v", got)
+	}
+	if got := builds(d.Removed); !reflect.DeepEqual(got, []string{"13C5066c"}) {
+		t.Errorf("Removed = %v", got)
+	}
+
+	if len(d.Changed) != 2 {
+		t.Fatalf("Changed = %v, want 13C90 and 13A1030d", d.Changed)
+	}
+	p := d.Changed[0]
+	if p.Build != "13C90" || p.Old != old[0] || p.New != next[1] {
+		t.Errorf("Changed[0] = %s %p %p, want the final 13.2 promoted from the RC", p.Build, p.Old, p.New)
+	}
+	if !p.Promoted() {
+		t.Error("Changed[0].Promoted() = false, want true")
+	}
+
+	c := d.Changed[1]
+	if c.Build != "13A1030d" || c.Old != old[2] || c.New != next[3] {
+		t.Errorf("Changed[1] = %s %p %p", c.Build, c.Old, c.New)
+	}
+	want := []FieldChange{
+		{Path: "checksums.sha1", Old: `"0123456789abcdef0123456789abcdef01234567"`, New: `"76543210fedcba9876543210fedcba9876543210"`},
+		{Path: "requires", Old: `""`, New: `"11.3"`},
+	}
+	if !reflect.DeepEqual(c.Fields, want) {
+		t.Errorf("Changed[1].Fields = %v, want %v", c.Fields, want)
+	}
+	if f := c.Field("requires"); f == nil || f.New != `"11.3"` {
+		t.Errorf("Field(requires) = %v", f)
+	}
+	if f := c.Field("name"); f != nil {
+		t.Errorf("Field(name) = %v, want nil", f)
+	}
+	if c.Promoted() {
+		t.Error("Promoted() = true for the same channel")
+	}
+	if d.Empty() {
+		t.Error("Empty() = true")
+	}
+}
+
+func TestDiffPromoted(t *testing.T) {
+	old := testReleases(t, "13.0 RC (13A233)")
+	next := testReleases(t, "13.0 (13A233)")
+
+	d, err := Diff(old, next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Added) != 0 || len(d.Removed) != 0 || len(d.Changed) != 1 {
+		t.Fatalf("Diff() = %+v, want a single change", d)
+	}
+
+	c := d.Changed[0]
+	if !c.Promoted() {
+		t.Error("Promoted() = false, want true")
+	}
+	for _, path := range []string{"version.release.rc", "version.release.release"} {
+		if c.Field(path) == nil {
+			t.Errorf("Field(%q) = nil", path)
+		}
+	}
+
+	const want = "~ 13.0 (13A233)\n" +
+		"    version.release.rc: 1 -> (none)\n" +
+		"    version.release.release: false -> true\n"
+	if got := d.String(); got != want {
+		t.Errorf("String() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDiffEmpty(t *testing.T) {
+	old := testReleases(t, "13.2.1 (13C100)", "13.2 (13C90)")
+	next := testReleases(t, "13.2.1 (13C100)", "13.2 (13C90)")
+
+	d, err := Diff(old, next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.Empty() {
+		t.Errorf("Diff() = %+v, want empty", d)
+	}
+	if s := d.String(); s != "" {
+		t.Errorf("String() = %q, want empty", s)
+	}
+}
+
+func builds(xrs []*XcodeRelease) []string {
+	var bs []string
+	for _, xr := range xrs {
+		bs = append(bs, xr.Version.Build)
+	}
+
+	return bs
+}
@@ -0,0 +1,345 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// partSuffix is the suffix of the partially downloaded files.
+const partSuffix = ".part"
+
+// Progress is the progress of a download reported to Downloader.Progress.
+type Progress struct {
+	// Release is the downloading release.
+	Release *XcodeRelease
+
+	// Path is the destination file path.
+	Path string
+
+	// Written is the number of bytes on disk, including the resumed part.
+	Written int64
+
+	// Total is the size of the archive, or -1 if unknown.
+	Total int64
+}
+
+// Downloader downloads the Xcode archives from Links.Download.
+//
+// Partially downloaded files are kept with the ".part" suffix and resumed with HTTP Range requests.
+// The downloaded archive is verified against the release checksum if the release has one.
+type Downloader struct {
+	// HTTPClient is the HTTP client. If nil, http.DefaultClient is used.
+	HTTPClient *http.Client
+
+	// Header is the additional header sent with each request, e.g. Authorization.
+	Header http.Header
+
+	// Cookies is the cookies sent with each request, e.g. the Apple Developer download authorization.
+	Cookies []*http.Cookie
+
+	// UserAgent overrides the User-Agent header if not empty.
+	UserAgent string
+
+	// Progress is called as the download proceeds. It may be called concurrently by the concurrent downloads.
+	Progress func(Progress)
+
+	// MaxConcurrent limits the number of the concurrent downloads of the Downloader. Zero means no limit.
+	MaxConcurrent int
+
+	// Retry is the retry policy of the failed requests, resuming from the downloaded part. If nil, requests are not retried.
+	Retry *RetryPolicy
+
+	// SkipVerify disables the checksum verification on completion.
+	SkipVerify bool
+
+	semOnce sync.Once
+	sem     chan struct{}
+}
+
+func (d *Downloader) acquire(ctx context.Context) (release func(), err error) {
+	d.semOnce.Do(func() {
+		if d.MaxConcurrent > 0 {
+			d.sem = make(chan struct{}, d.MaxConcurrent)
+		}
+	})
+	if d.sem == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case d.sem <- struct{}{}:
+		return func() { <-d.sem }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// ArchiveName returns the file name of the archive of xr, e.g. "Xcode_13.2.1.xip".
+func ArchiveName(xr *XcodeRelease) string {
+	u := xr.Links.Download.URL
+	if i := strings.IndexAny(u, "?#"); i >= 0 {
+		u = u[:i]
+	}
+
+	return path.Base(u)
+}
+
+// Download downloads the archive of xr to the file dst, resuming dst.part if it exists.
+func (d *Downloader) Download(ctx context.Context, xr *XcodeRelease, dst string) error {
+	src := xr.Links.Download.URL
+	if src == "" {
+		return fmt.Errorf("download %s: no download URL", releaseTitle(xr))
+	}
+
+	release, err := d.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	part := dst + partSuffix
+	err = d.Retry.retry(ctx, func() error {
+		return d.fetch(ctx, xr, src, dst, part)
+	})
+	if err != nil {
+		return fmt.Errorf("download %s: %w", releaseTitle(xr), err)
+	}
+
+	if !d.SkipVerify && xr.Checksums.Sha1 != "" {
+		if err := VerifyFile(part, xr); err != nil {
+			os.Remove(part) // corrupted, start over next time
+			return err
+		}
+	}
+
+	return os.Rename(part, dst)
+}
+
+// fetch downloads src into part, resuming from its current size.
+func (d *Downloader) fetch(ctx context.Context, xr *XcodeRelease, src, dst, part string) error {
+	var offset int64
+	if fi, err := os.Stat(part); err == nil {
+		offset = fi.Size()
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, src, nil)
+	if err != nil {
+		return err
+	}
+	for k, vs := range d.Header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	for _, c := range d.Cookies {
+		req.AddCookie(c)
+	}
+	if d.UserAgent != "" {
+		req.Header.Set("User-Agent", d.UserAgent)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+	}
+
+	hc := d.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	total := int64(-1)
+	flag := os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		start, size, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if !ok || start != offset {
+			return fmt.Errorf("unexpected Content-Range %q for offset %d", resp.Header.Get("Content-Range"), offset)
+		}
+		total = size
+
+	case http.StatusOK:
+		// the server ignored the Range header, start over
+		offset = 0
+		flag = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		if resp.ContentLength >= 0 {
+			total = resp.ContentLength
+		}
+
+	case http.StatusRequestedRangeNotSatisfiable:
+		// the part may be already complete
+		if _, size, ok := parseContentRange(resp.Header.Get("Content-Range")); ok && size == offset {
+			d.report(xr, dst, offset, size)
+			return nil
+		}
+		if err := os.Remove(part); err != nil {
+			return err
+		}
+		return newHTTPError(resp)
+
+	default:
+		return newHTTPError(resp)
+	}
+
+	f, err := os.OpenFile(part, flag, 0o644)
+	if err != nil {
+		return err
+	}
+
+	pw := &progressWriter{w: f, d: d, xr: xr, path: dst, written: offset, total: total}
+	d.report(xr, dst, offset, total)
+	if _, err := io.Copy(pw, resp.Body); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if total >= 0 && pw.written != total {
+		return fmt.Errorf("short download: %d of %d bytes: %w", pw.written, total, io.ErrUnexpectedEOF)
+	}
+
+	return nil
+}
+
+func (d *Downloader) report(xr *XcodeRelease, dst string, written, total int64) {
+	if d.Progress != nil {
+		d.Progress(Progress{Release: xr, Path: dst, Written: written, Total: total})
+	}
+}
+
+// progressWriter reports the progress of the writes to w.
+type progressWriter struct {
+	w       io.Writer
+	d       *Downloader
+	xr      *XcodeRelease
+	path    string
+	written int64
+	total   int64
+}
+
+func (pw *progressWriter) Write(p []byte) (int, error) {
+	n, err := pw.w.Write(p)
+	pw.written += int64(n)
+	pw.d.report(pw.xr, pw.path, pw.written, pw.total)
+
+	return n, err
+}
+
+// parseContentRange parses the Content-Range header such as "bytes 100-199/1000" or "bytes */1000".
+// The size is -1 if unknown.
+func parseContentRange(v string) (start, size int64, ok bool) {
+	v = strings.TrimSpace(v)
+	if !strings.HasPrefix(v, "bytes ") {
+		return 0, 0, false
+	}
+	v = strings.TrimSpace(v[len("bytes "):])
+
+	slash := strings.IndexByte(v, '/')
+	if slash < 0 {
+		return 0, 0, false
+	}
+	rng, sz := v[:slash], v[slash+1:]
+
+	size = -1
+	if sz != "*" {
+		n, err := strconv.ParseInt(sz, 10, 64)
+		if err != nil {
+			return 0, 0, false
+		}
+		size = n
+	}
+
+	if rng == "*" {
+		return 0, size, true
+	}
+	dash := strings.IndexByte(rng, '-')
+	if dash < 0 {
+		return 0, 0, false
+	}
+	start, err := strconv.ParseInt(rng[:dash], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return start, size, true
+}
+
+// DownloadAll downloads the archives of xrs into the directory dir concurrently, limited by MaxConcurrent.
+//
+// The releases sharing a download URL, such as a RC and the final release built from it, are downloaded once.
+// It returns an error without downloading anything if the different URLs have the same ArchiveName.
+// Otherwise it waits for all downloads and returns the first error.
+func (d *Downloader) DownloadAll(ctx context.Context, xrs []*XcodeRelease, dir string) error {
+	xrs, err := uniqueArchives(xrs)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	for _, xr := range xrs {
+		wg.Add(1)
+		go func(xr *XcodeRelease) {
+			defer wg.Done()
+
+			if err := d.Download(ctx, xr, filepath.Join(dir, ArchiveName(xr))); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+			}
+		}(xr)
+	}
+	wg.Wait()
+
+	return firstErr
+}
+
+// uniqueArchives returns xrs without the releases whose download URL is listed earlier.
+// It returns an error if the different URLs have the same ArchiveName.
+func uniqueArchives(xrs []*XcodeRelease) ([]*XcodeRelease, error) {
+	names := make(map[string]*XcodeRelease, len(xrs))
+
+	uniq := make([]*XcodeRelease, 0, len(xrs))
+	for _, xr := range xrs {
+		name := ArchiveName(xr)
+		if prev, ok := names[name]; ok {
+			if prev.Links.Download.URL == xr.Links.Download.URL {
+				continue
+			}
+			return nil, fmt.Errorf("download %s and %s: both archives are named %q",
+				releaseTitle(prev), releaseTitle(xr), name)
+		}
+		names[name] = xr
+		uniq = append(uniq, xr)
+	}
+
+	return uniq, nil
+}
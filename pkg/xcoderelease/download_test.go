@@ -0,0 +1,172 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var testArchive = bytes.Repeat([]byte("0123456789abcdef"), 4096)
+
+func testArchiveSHA1() string {
+	sum := sha1.Sum(testArchive)
+	return hex.EncodeToString(sum[:])
+}
+
+// newArchiveServer returns the server of testArchive, which honors the Range requests unless ignoreRange.
+// The Range headers of the requests are appended to ranges.
+func newArchiveServer(t *testing.T, ignoreRange bool, ranges *[]string) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*ranges = append(*ranges, r.Header.Get("Range"))
+		if ignoreRange {
+			w.Header().Set("Content-Length", strconv.Itoa(len(testArchive)))
+			w.Write(testArchive)
+			return
+		}
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(testArchive))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestDownload(t *testing.T) {
+	half := int64(len(testArchive) / 2)
+
+	tests := []struct {
+		name        string
+		part        []byte // the existing .part file, or nil
+		ignoreRange bool
+		wantRange   string
+	}{
+		{name: "New"},
+		{name: "Resume", part: testArchive[:half], wantRange: "bytes=" + strconv.FormatInt(half, 10) + "-"},
+		{name: "CompletePart", part: testArchive, wantRange: "bytes=" + strconv.Itoa(len(testArchive)) + "-"},
+		{name: "RangeIgnored", part: []byte("garbage"), ignoreRange: true, wantRange: "bytes=7-"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var ranges []string
+			srv := newArchiveServer(t, tt.ignoreRange, &ranges)
+
+			dst := filepath.Join(t.TempDir(), "Xcode_13.2.1.xip")
+			if tt.part != nil {
+				if err := os.WriteFile(dst+partSuffix, tt.part, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			xr := testRelease(t, "13.2.1 (13C100)")
+			xr.Links.Download.URL = srv.URL + "/Xcode_13.2.1.xip"
+			xr.Checksums.Sha1 = testArchiveSHA1()
+
+			var last Progress
+			d := &Downloader{Progress: func(p Progress) { last = p }}
+			if err := d.Download(context.Background(), xr, dst); err != nil {
+				t.Fatalf("Download() error = %v", err)
+			}
+
+			got, err := os.ReadFile(dst)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, testArchive) {
+				t.Errorf("downloaded %d bytes, want the %d bytes of the archive", len(got), len(testArchive))
+			}
+			if _, err := os.Stat(dst + partSuffix); !errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("the .part file is left: %v", err)
+			}
+			if len(ranges) != 1 || ranges[0] != tt.wantRange {
+				t.Errorf("Range headers = %q, want [%q]", ranges, tt.wantRange)
+			}
+			if last.Written != int64(len(testArchive)) || last.Total != int64(len(testArchive)) {
+				t.Errorf("last Progress = %d/%d, want %d", last.Written, last.Total, len(testArchive))
+			}
+		})
+	}
+}
+
+func TestDownloadChecksumMismatch(t *testing.T) {
+	var ranges []string
+	srv := newArchiveServer(t, false, &ranges)
+
+	dst := filepath.Join(t.TempDir(), "Xcode_13.2.1.xip")
+	xr := testRelease(t, "13.2.1 (13C100)")
+	xr.Links.Download.URL = srv.URL + "/Xcode_13.2.1.xip"
+	xr.Checksums.Sha1 = strings.Repeat("0", 40)
+
+	err := new(Downloader).Download(context.Background(), xr, dst)
+
+	var merr *ChecksumMismatchError
+	if !errors.As(err, &merr) || merr.Got != testArchiveSHA1() {
+		t.Fatalf("Download() error = %v, want *ChecksumMismatchError", err)
+	}
+	for _, path := range []string{dst, dst + partSuffix} {
+		if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("%s is left: %v", filepath.Base(path), err)
+		}
+	}
+
+	// SkipVerify keeps the archive
+	if err := (&Downloader{SkipVerify: true}).Download(context.Background(), xr, dst); err != nil {
+		t.Fatalf("Download() with SkipVerify error = %v", err)
+	}
+}
+
+func TestDownloadAll(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(testArchive))
+	}))
+	defer srv.Close()
+
+	rc := testRelease(t, "13.2 RC (13C90)")
+	final := testRelease(t, "13.2 (13C90)")
+	other := testRelease(t, "13.2.1 (13C100)")
+	rc.Links.Download.URL = srv.URL + "/13.2/Xcode_13.2.xip"
+	final.Links.Download.URL = srv.URL + "/13.2/Xcode_13.2.xip"
+	other.Links.Download.URL = srv.URL + "/13.2.1/Xcode_13.2.1.xip"
+
+	dir := t.TempDir()
+	d := &Downloader{MaxConcurrent: 1}
+	if err := d.DownloadAll(context.Background(), []*XcodeRelease{rc, final, other}, dir); err != nil {
+		t.Fatalf("DownloadAll() error = %v", err)
+	}
+	if n := atomic.LoadInt32(&hits); n != 2 {
+		t.Errorf("DownloadAll() fetched %d times, want 2", n)
+	}
+	for _, name := range []string{"Xcode_13.2.xip", "Xcode_13.2.1.xip"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Error(err)
+		}
+	}
+
+	// the same name of the different URLs
+	atomic.StoreInt32(&hits, 0)
+	other.Links.Download.URL = srv.URL + "/mirror/Xcode_13.2.xip"
+	if err := d.DownloadAll(context.Background(), []*XcodeRelease{final, other}, t.TempDir()); err == nil {
+		t.Error("DownloadAll() with the colliding names error = nil")
+	}
+	if n := atomic.LoadInt32(&hits); n != 0 {
+		t.Errorf("DownloadAll() fetched %d times before the error, want 0", n)
+	}
+}
@@ -0,0 +1,230 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	json "github.com/goccy/go-json"
+)
+
+// Format represents an output format of the releases.
+type Format string
+
+// List of formats.
+const (
+	FormatJSON     Format = "json"
+	FormatCSV      Format = "csv"
+	FormatYAML     Format = "yaml"
+	FormatMarkdown Format = "markdown"
+)
+
+// ParseFormat parses the format name s case-insensitively. It also accepts "yml" and "md".
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatJSON, FormatCSV, FormatYAML, FormatMarkdown:
+		return f, nil
+	case "yml":
+		return FormatYAML, nil
+	case "md":
+		return FormatMarkdown, nil
+	default:
+		return "", fmt.Errorf("unknown format %q", s)
+	}
+}
+
+// Encode writes xrs to w in the format f.
+func Encode(w io.Writer, f Format, xrs []*XcodeRelease) error {
+	switch f {
+	case FormatJSON:
+		data, err := Marshal(xrs)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append(data, '\n'))
+		return err
+	case FormatCSV:
+		return EncodeCSV(w, xrs)
+	case FormatYAML:
+		return EncodeYAML(w, xrs)
+	case FormatMarkdown:
+		return EncodeMarkdown(w, xrs)
+	default:
+		return fmt.Errorf("unknown format %q", f)
+	}
+}
+
+// Marshal returns the canonical data.json encoding of xrs, which Unmarshal reads back into the same releases.
+//
+// The fields are encoded in the struct order without HTML escaping, and the empty optional
+// fields such as Version.Release, Compilers.Gcc and Compilers.Llvm are omitted.
+// The Extra fields kept by UnmarshalLenient are not encoded.
+//
+// Marshal keeps every value of data.json, but not its bytes: the keys of data.json are in lexical order,
+// and the gm and release of Version.Release and the checksums are written even where data.json omits them.
+func Marshal(xrs []*XcodeRelease) ([]byte, error) {
+	if xrs == nil {
+		xrs = []*XcodeRelease{}
+	}
+
+	return marshalJSON(xrs)
+}
+
+// marshalJSON returns the compact JSON encoding of v without HTML escaping.
+func marshalJSON(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, fmt.Errorf("marshal data: %w", err)
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// Title returns the human readable version of xr without the build, e.g. "13.2 Beta 2".
+func (xr *XcodeRelease) Title() string {
+	v, err := xr.Version.XcodeVersion()
+	if err != nil {
+		return xr.Version.Number
+	}
+	v.Build = ""
+
+	return v.String()
+}
+
+// SDKNumbers returns the SDK version numbers of the platform p in xr joined by ", ".
+func (xr *XcodeRelease) SDKNumbers(p Platform) string {
+	var ns []string
+	for _, s := range xr.SDKs.For(p) {
+		ns = append(ns, s.Number)
+	}
+
+	return strings.Join(ns, ", ")
+}
+
+// CompilerNumbers returns the version numbers of the compiler kind in xr joined by ", ".
+func (xr *XcodeRelease) CompilerNumbers(kind Compiler) string {
+	return strings.Join(xr.Compilers.numbers(kind), ", ")
+}
+
+// platformsOf returns the platforms listed in any of xrs, the known ones first.
+func platformsOf(xrs []*XcodeRelease) []Platform {
+	seen := make(map[Platform]bool)
+	for _, xr := range xrs {
+		for _, p := range xr.SDKs.Platforms() {
+			seen[p] = true
+		}
+	}
+
+	var ps, others []Platform
+	for _, p := range knownPlatforms {
+		if seen[p] {
+			ps = append(ps, p)
+			delete(seen, p)
+		}
+	}
+	for p := range seen {
+		others = append(others, p)
+	}
+	sort.Slice(others, func(i, j int) bool { return others[i] < others[j] })
+
+	return append(ps, others...)
+}
+
+// EncodeCSV writes xrs to w as CSV with a header row, flattening each release into a row.
+//
+// The SDK columns are named "sdk_<platform>" for each platform listed in xrs, and
+// the multiple versions in a cell are separated by ", ".
+func EncodeCSV(w io.Writer, xrs []*XcodeRelease) error {
+	ps := platformsOf(xrs)
+
+	header := []string{"name", "version", "number", "channel", "build", "date", "requires"}
+	for _, p := range ps {
+		header = append(header, "sdk_"+string(p))
+	}
+	header = append(header, "swift", "clang", "sha1", "download_url", "notes_url")
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, xr := range xrs {
+		row := []string{
+			xr.Name,
+			xr.Title(),
+			xr.Version.Number,
+			xr.Version.Channel().String(),
+			xr.Version.Build,
+			xr.Date.String(),
+			xr.Requires,
+		}
+		for _, p := range ps {
+			row = append(row, xr.SDKNumbers(p))
+		}
+		row = append(row,
+			xr.CompilerNumbers(CompilerSwift),
+			xr.CompilerNumbers(CompilerClang),
+			xr.Checksums.Sha1,
+			xr.Links.Download.URL,
+			xr.Links.Notes.URL,
+		)
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// EncodeMarkdown writes xrs to w as a Markdown table.
+func EncodeMarkdown(w io.Writer, xrs []*XcodeRelease) error {
+	ps := platformsOf(xrs)
+
+	header := []string{"Version", "Build", "Date", "Requires"}
+	for _, p := range ps {
+		header = append(header, string(p)+" SDK")
+	}
+	header = append(header, "Swift", "Clang")
+
+	var buf bytes.Buffer
+	writeMarkdownRow(&buf, header)
+	sep := make([]string, len(header))
+	for i := range sep {
+		sep[i] = "---"
+	}
+	writeMarkdownRow(&buf, sep)
+
+	for _, xr := range xrs {
+		version := xr.Title()
+		if u := xr.Links.Notes.URL; u != "" {
+			version = "[" + version + "](" + u + ")"
+		}
+		row := []string{version, xr.Version.Build, xr.Date.String(), xr.Requires}
+		for _, p := range ps {
+			row = append(row, xr.SDKNumbers(p))
+		}
+		row = append(row, xr.CompilerNumbers(CompilerSwift), xr.CompilerNumbers(CompilerClang))
+		writeMarkdownRow(&buf, row)
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func writeMarkdownRow(buf *bytes.Buffer, cells []string) {
+	buf.WriteByte('|')
+	for _, c := range cells {
+		buf.WriteByte(' ')
+		buf.WriteString(strings.NewReplacer("|", `\|`, "\n", " ").Replace(c))
+		buf.WriteString(" |")
+	}
+	buf.WriteByte('\n')
+}
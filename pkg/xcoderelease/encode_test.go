@@ -0,0 +1,175 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	json "github.com/goccy/go-json"
+)
+
+func TestMarshalRoundTrip(t *testing.T) {
+	const data = `[` +
+		`{"name":"Xcode","sdks":{"iOS":[{"build":"19C51","number":"15.2","release":true}],"macOS":[{"build":"21C46","number":"12.1","release":true}],"visionOS":[{"build":"21N5165g","number":"1.0","release":false}]},` +
+		`"version":{"build":"13C100","number":"13.2.1","release":{"gm":false,"release":true}},"requires":"11.3",` +
+		`"compilers":{"clang":[{"build":"1300.0.29.30","number":"13.0.0","release":true}],"swift":[{"build":"swiftlang-1300.0.47.5","number":"5.5.2","release":true}]},` +
+		`"checksums":{"sha1":"b8ffd8a5ed3cdfa0b8e4a26d7f3a1c6d9c7d3f1e"},"date":{"day":17,"month":12,"year":2021},` +
+		`"links":{"download":{"url":"https://download.developer.apple.com/Developer_Tools/Xcode_13.2.1/Xcode_13.2.1.xip?a=1&b=2"},"notes":{"url":""}}},` +
+		`{"name":"Xcode","sdks":null,"version":{"build":"4A2002a","number":"4.0"},"requires":"10.6.6",` +
+		`"compilers":{"clang":null,"gcc":[{"build":"5666.3","number":"4.2","release":true}],"llvm":[{"number":"2.9","release":true}],"llvm_gcc":[{"build":"2335.9","number":"4.2","release":true}],"swift":null},` +
+		`"checksums":{},"date":{"day":9,"month":3,"year":2011},"links":{"download":{"url":""},"notes":{"url":""}}}` +
+		`]`
+
+	xrs, err := Unmarshal([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Marshal(xrs)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if string(got) != data {
+		t.Errorf("Marshal() =\n%s\nwant\n%s", got, data)
+	}
+
+	again, err := Unmarshal(got)
+	if err != nil {
+		t.Fatalf("Unmarshal(Marshal()) error = %v", err)
+	}
+	if !reflect.DeepEqual(again, xrs) {
+		t.Errorf("Unmarshal(Marshal()) = %+v, want %+v", again, xrs)
+	}
+}
+
+// testDataJSON is shaped like the entries of data.json: the keys are in lexical order, the release of a
+// version has no gm, and a beta has no checksums.
+const testDataJSON = `[
+  {
+    "checksums": {
+      "sha1": "b8ffd8a5ed3cdfa0b8e4a26d7f3a1c6d9c7d3f1e"
+    },
+    "compilers": {
+      "clang": [{"build": "1300.0.29.30", "number": "13.0.0", "release": true}],
+      "swift": [{"build": "5.5.2.1.5", "number": "5.5.2", "release": true}]
+    },
+    "date": {"day": 17, "month": 12, "year": 2021},
+    "links": {
+      "download": {"url": "https://download.developer.apple.com/Developer_Tools/Xcode_13.2.1/Xcode_13.2.1.xip"},
+      "notes": {"url": "https://developer.apple.com/documentation/xcode-release-notes/xcode-13_2-release-notes"}
+    },
+    "name": "Xcode",
+    "requires": "11.3",
+    "sdks": {
+      "iOS": [{"build": "19C51", "number": "15.2", "release": true}],
+      "macOS": [{"build": "21C46", "number": "12.1", "release": true}],
+      "tvOS": [{"build": "19K50", "number": "15.2", "release": true}],
+      "watchOS": [{"build": "19S51", "number": "8.3", "release": true}]
+    },
+    "version": {"build": "13C100", "number": "13.2.1", "release": {"release": true}}
+  },
+  {
+    "compilers": {
+      "clang": [{"build": "1316.0.20.6", "number": "13.1.6", "release": true}],
+      "swift": [{"build": "5.6.0.320.1", "number": "5.6", "release": false}]
+    },
+    "date": {"day": 27, "month": 1, "year": 2022},
+    "links": {
+      "download": {"url": "https://download.developer.apple.com/Developer_Tools/Xcode_13.3_beta/Xcode_13.3_beta.xip"},
+      "notes": {"url": "https://developer.apple.com/documentation/xcode-release-notes/xcode-13_3-release-notes"}
+    },
+    "name": "Xcode",
+    "requires": "12.0",
+    "sdks": {
+      "iOS": [{"build": "19E5219e", "number": "15.4", "release": false}],
+      "macOS": [{"build": "21E5196i", "number": "12.3", "release": false}]
+    },
+    "version": {"build": "13E5086k", "number": "13.3", "release": {"beta": 1}}
+  }
+]`
+
+// TestMarshalDataJSON checks that Marshal keeps every value of data.json. The accepted differences are:
+//   - the keys are in the struct order, and the whitespace is dropped;
+//   - the missing gm and release of a version release are written as false;
+//   - the missing checksums are written as the empty object.
+func TestMarshalDataJSON(t *testing.T) {
+	xrs, err := Unmarshal([]byte(testDataJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := Marshal(xrs)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	flat := func(data []byte) map[string]string {
+		t.Helper()
+		var v interface{}
+		if err := json.Unmarshal(data, &v); err != nil {
+			t.Fatal(err)
+		}
+		m := make(map[string]string)
+		flattenValue(m, "", v)
+		return m
+	}
+	in, got := flat([]byte(testDataJSON)), flat(out)
+	for path, v := range in {
+		if got[path] != v {
+			t.Errorf("Marshal() %s = %s, want %s", path, got[path], v)
+		}
+	}
+	for path, v := range got {
+		if _, ok := in[path]; ok {
+			continue
+		}
+		accepted := (strings.HasSuffix(path, ".version.release.gm") || strings.HasSuffix(path, ".version.release.release")) && v == "false"
+		if !accepted {
+			t.Errorf("Marshal() added %s = %s", path, v)
+		}
+	}
+	if !strings.Contains(string(out), `"checksums":{},`) {
+		t.Errorf("Marshal() = %s, want the empty checksums of the beta", out)
+	}
+
+	// the output is canonical
+	again, err := Unmarshal(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(again, xrs) {
+		t.Errorf("Unmarshal(Marshal()) = %+v, want %+v", again, xrs)
+	}
+	out2, err := Marshal(again)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out2, out) {
+		t.Errorf("Marshal(Unmarshal(Marshal())) =\n%s\nwant\n%s", out2, out)
+	}
+}
+
+func TestMarshalNil(t *testing.T) {
+	got, err := Marshal(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "[]" {
+		t.Errorf("Marshal(nil) = %s, want []", got)
+	}
+}
+
+func TestMarshalNoHTMLEscape(t *testing.T) {
+	xrs := []*XcodeRelease{{Name: "Xcode <beta> & more"}}
+
+	got, err := Marshal(xrs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(got), `"Xcode <beta> & more"`) {
+		t.Errorf("Marshal() = %s, want the name unescaped", got)
+	}
+}
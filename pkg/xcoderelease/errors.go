@@ -0,0 +1,82 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxErrorBody is the maximum size of the response body kept in HTTPError.
+const maxErrorBody = 512
+
+// HTTPError is returned when the server responds with a non-2xx status code.
+type HTTPError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+
+	// Status is the HTTP status line of the response, e.g. "404 Not Found".
+	Status string
+
+	// URL is the requested URL.
+	URL string
+
+	// Body is the head of the response body, truncated to 512 bytes.
+	Body []byte
+
+	// RetryAfter is the parsed Retry-After header, or zero if the header is absent.
+	RetryAfter time.Duration
+}
+
+// newHTTPError returns the new HTTPError from resp, consuming the head of resp.Body.
+func newHTTPError(resp *http.Response) *HTTPError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+
+	return &HTTPError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		URL:        resp.Request.URL.String(),
+		Body:       body,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}
+}
+
+// Error implements error.
+func (e *HTTPError) Error() string {
+	if len(e.Body) == 0 {
+		return fmt.Sprintf("GET %s: %s", e.URL, e.Status)
+	}
+
+	return fmt.Sprintf("GET %s: %s: %q", e.URL, e.Status, e.Body)
+}
+
+// Temporary reports whether the request may succeed if retried.
+func (e *HTTPError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError
+}
+
+// parseRetryAfter parses the Retry-After header value, which is either delay seconds or an HTTP date.
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	if v == "" {
+		return 0
+	}
+
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,313 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// FeedFormat represents a syndication feed format.
+type FeedFormat string
+
+// List of feed formats.
+const (
+	FeedAtom FeedFormat = "atom"
+	FeedRSS  FeedFormat = "rss"
+)
+
+// ParseFeedFormat parses the feed format name s case-insensitively.
+func ParseFeedFormat(s string) (FeedFormat, error) {
+	switch f := FeedFormat(strings.ToLower(s)); f {
+	case FeedAtom, FeedRSS:
+		return f, nil
+	case "rss2":
+		return FeedRSS, nil
+	default:
+		return "", fmt.Errorf("unknown feed format %q", s)
+	}
+}
+
+// Default feed metadata.
+const (
+	DefaultFeedTitle = "Xcode Releases"
+	DefaultFeedLink  = "https://xcodereleases.com/"
+)
+
+// Feed generates the Atom and RSS 2.0 feeds of the releases.
+//
+// An entry of the feed is titled by the version with the channel and the build, e.g. "Xcode 13.2 Beta 2 (13C5081f)",
+// dated by the release date, and links to the release notes and the download.
+type Feed struct {
+	// Title is the title of the feed. The zero value uses DefaultFeedTitle.
+	Title string
+
+	// Link is the URL of the website of the feed. The zero value uses DefaultFeedLink.
+	Link string
+
+	// Self is the URL the feed is served at, if any.
+	Self string
+
+	// Channels, if not empty, limits the entries to the releases in any of them.
+	Channels []Channel
+
+	// Limit, if positive, limits the number of the entries.
+	Limit int
+}
+
+// Encode writes the feed of xrs to w in the format ff.
+func (f *Feed) Encode(w io.Writer, ff FeedFormat, xrs []*XcodeRelease) error {
+	switch ff {
+	case FeedAtom:
+		return f.EncodeAtom(w, xrs)
+	case FeedRSS:
+		return f.EncodeRSS(w, xrs)
+	default:
+		return fmt.Errorf("unknown feed format %q", ff)
+	}
+}
+
+// entries returns the releases in xrs the feed lists, newest first.
+func (f *Feed) entries(xrs []*XcodeRelease) []*XcodeRelease {
+	q := NewIndex(xrs).Query()
+	if len(f.Channels) > 0 {
+		q = q.Channel(f.Channels...)
+	}
+
+	entries := q.All()
+	if f.Limit > 0 && len(entries) > f.Limit {
+		entries = entries[:f.Limit]
+	}
+
+	return entries
+}
+
+func (f *Feed) title() string {
+	if f.Title != "" {
+		return f.Title
+	}
+	if len(f.Channels) > 0 {
+		names := make([]string, len(f.Channels))
+		for i, ch := range f.Channels {
+			names[i] = ch.String()
+		}
+		return DefaultFeedTitle + " (" + strings.Join(names, ", ") + ")"
+	}
+
+	return DefaultFeedTitle
+}
+
+func (f *Feed) link() string {
+	if f.Link != "" {
+		return f.Link
+	}
+
+	return DefaultFeedLink
+}
+
+// entryTitle returns the title of the feed entry of xr.
+func entryTitle(xr *XcodeRelease) string {
+	return "Xcode " + releaseTitle(xr)
+}
+
+// entryIDDate is the date of the tag URIs of the feed entries.
+const entryIDDate = "2021"
+
+// entryID returns the tag URI identifying the feed entry of xr, e.g. "tag:xcodereleases.com,2021:13C5081f/13.2-Beta-2".
+//
+// The ID is made of the build and the version only, so that a corrected release date keeps the entry.
+// The version is part of the ID since a RC and the final release built from it share the build.
+func entryID(xr *XcodeRelease) string {
+	return fmt.Sprintf("tag:xcodereleases.com,%s:%s/%s", entryIDDate, xr.Version.Build, strings.ReplaceAll(xr.Title(), " ", "-"))
+}
+
+// entrySummary returns the plain text summary of the feed entry of xr.
+func entrySummary(xr *XcodeRelease) string {
+	var lines []string
+
+	if xr.Requires != "" {
+		lines = append(lines, "Requires macOS "+xr.Requires)
+	}
+	var sdks []string
+	for _, p := range xr.SDKs.Platforms() {
+		sdks = append(sdks, string(p)+" "+xr.SDKNumbers(p))
+	}
+	if len(sdks) > 0 {
+		lines = append(lines, "SDKs: "+strings.Join(sdks, ", "))
+	}
+	if s := xr.CompilerNumbers(CompilerSwift); s != "" {
+		lines = append(lines, "Swift "+s)
+	}
+	if u := xr.Links.Download.URL; u != "" {
+		lines = append(lines, "Download: "+u)
+	}
+	if u := xr.Links.Notes.URL; u != "" {
+		lines = append(lines, "Release notes: "+u)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	Title   string     `xml:"title"`
+	ID      string     `xml:"id"`
+	Updated string     `xml:"updated"`
+	Links   []atomLink `xml:"link"`
+	Summary string     `xml:"summary"`
+}
+
+// EncodeAtom writes the Atom feed of xrs to w.
+func (f *Feed) EncodeAtom(w io.Writer, xrs []*XcodeRelease) error {
+	entries := f.entries(xrs)
+
+	feed := atomFeed{
+		Title:  f.title(),
+		ID:     f.link(),
+		Links:  []atomLink{{Rel: "alternate", Href: f.link()}},
+		Author: atomAuthor{Name: "Apple"},
+	}
+	if f.Self != "" {
+		feed.ID = f.Self
+		feed.Links = append(feed.Links, atomLink{Rel: "self", Type: "application/atom+xml", Href: f.Self})
+	}
+
+	// The feed is as new as its newest entry, which keeps the output stable for the same releases.
+	updated := time.Unix(0, 0).UTC()
+	if len(entries) > 0 {
+		updated = entries[0].Date.Time()
+	}
+	feed.Updated = updated.Format(time.RFC3339)
+
+	for _, xr := range entries {
+		e := atomEntry{
+			Title:   entryTitle(xr),
+			ID:      entryID(xr),
+			Updated: xr.Date.Time().Format(time.RFC3339),
+			Summary: entrySummary(xr),
+		}
+		if u := xr.Links.Notes.URL; u != "" {
+			e.Links = append(e.Links, atomLink{Rel: "alternate", Type: "text/html", Href: u})
+		}
+		if u := xr.Links.Download.URL; u != "" {
+			e.Links = append(e.Links, atomLink{Rel: "enclosure", Href: u})
+		}
+		feed.Entries = append(feed.Entries, e)
+	}
+
+	return writeXML(w, feed)
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr,omitempty"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	AtomLink      *atomLink `xml:"atom:link,omitempty"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link,omitempty"`
+	Description string        `xml:"description"`
+	GUID        rssGUID       `xml:"guid"`
+	PubDate     string        `xml:"pubDate"`
+	Enclosure   *rssEnclosure `xml:"enclosure,omitempty"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int64  `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+// EncodeRSS writes the RSS 2.0 feed of xrs to w.
+//
+// The download of a release is the enclosure of the item. RSS 2.0 requires the length of an enclosure, but
+// data.json lacks the archive sizes, so the length is written as 0.
+func (f *Feed) EncodeRSS(w io.Writer, xrs []*XcodeRelease) error {
+	entries := f.entries(xrs)
+
+	feed := rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:       f.title(),
+			Link:        f.link(),
+			Description: "The Xcode releases listed on xcodereleases.com.",
+		},
+	}
+	if f.Self != "" {
+		feed.AtomNS = "http://www.w3.org/2005/Atom"
+		feed.Channel.AtomLink = &atomLink{Rel: "self", Type: "application/rss+xml", Href: f.Self}
+	}
+	if len(entries) > 0 {
+		feed.Channel.LastBuildDate = entries[0].Date.Time().Format(time.RFC1123Z)
+	}
+
+	for _, xr := range entries {
+		item := rssItem{
+			Title:       entryTitle(xr),
+			Link:        xr.Links.Notes.URL,
+			Description: entrySummary(xr),
+			GUID:        rssGUID{Value: entryID(xr)},
+			PubDate:     xr.Date.Time().Format(time.RFC1123Z),
+		}
+		if u := xr.Links.Download.URL; u != "" {
+			item.Enclosure = &rssEnclosure{URL: u, Type: "application/octet-stream"}
+		}
+		feed.Channel.Items = append(feed.Channel.Items, item)
+	}
+
+	return writeXML(w, feed)
+}
+
+func writeXML(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("encode feed: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+
+	return err
+}
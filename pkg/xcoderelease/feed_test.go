@@ -0,0 +1,42 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestEntryID(t *testing.T) {
+	rc := testRelease(t, "13.2 RC (13C90)")
+	final := testRelease(t, "13.2 (13C90)")
+
+	if got, want := entryID(rc), "tag:xcodereleases.com,2021:13C90/13.2-RC"; got != want {
+		t.Errorf("entryID(RC) = %q, want %q", got, want)
+	}
+	if entryID(rc) == entryID(final) {
+		t.Errorf("entryID(RC) = entryID(final) = %q", entryID(rc))
+	}
+
+	id := entryID(final)
+	final.Date = Date{Year: 2021, Month: 12, Day: 13}
+	if got := entryID(final); got != id {
+		t.Errorf("entryID() changed with the date: %q, was %q", got, id)
+	}
+}
+
+func TestEncodeRSSEnclosure(t *testing.T) {
+	xr := testRelease(t, "13.2 (13C90)")
+	xr.Links.Download.URL = "https://download.developer.apple.com/Xcode_13.2.xip"
+
+	var buf bytes.Buffer
+	if err := new(Feed).EncodeRSS(&buf, []*XcodeRelease{xr}); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, `<enclosure url="https://download.developer.apple.com/Xcode_13.2.xip" length="0" type="application/octet-stream">`) {
+		t.Errorf("EncodeRSS() =\n%s\nwant the enclosure with the length 0", out)
+	}
+}
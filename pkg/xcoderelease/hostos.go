@@ -0,0 +1,90 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OSVersion represents a parsed, comparable macOS version such as "10.15.4".
+type OSVersion struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+// ParseOSVersion parses the dotted macOS version s.
+func ParseOSVersion(s string) (OSVersion, error) {
+	major, minor, patch, err := parseNumber(strings.TrimSpace(s))
+	if err != nil {
+		return OSVersion{}, fmt.Errorf("parse macOS version: %w", err)
+	}
+
+	return OSVersion{Major: major, Minor: minor, Patch: patch}, nil
+}
+
+// String returns the dotted form of v, omitting the zero patch number.
+func (v OSVersion) String() string {
+	if v.Patch == 0 {
+		return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+	}
+
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Compare returns -1, 0 or +1 depending on whether v is older, the same or newer than w.
+func (v OSVersion) Compare(w OSVersion) int {
+	if c := compareInts(v.Major, w.Major); c != 0 {
+		return c
+	}
+	if c := compareInts(v.Minor, w.Minor); c != 0 {
+		return c
+	}
+
+	return compareInts(v.Patch, w.Patch)
+}
+
+// Less reports whether v is older than w.
+func (v OSVersion) Less(w OSVersion) bool {
+	return v.Compare(w) < 0
+}
+
+// MinHostOS parses the Requires field into the minimum macOS version which runs xr.
+func (xr *XcodeRelease) MinHostOS() (OSVersion, error) {
+	return ParseOSVersion(xr.Requires)
+}
+
+// RunsOn returns the Filter matching the releases which run on the host macOS version.
+//
+// The releases whose Requires field fails to parse never match.
+func RunsOn(host OSVersion) Filter {
+	return func(xr *XcodeRelease) bool {
+		min, err := xr.MinHostOS()
+		return err == nil && min.Compare(host) <= 0
+	}
+}
+
+// RunsOn narrows q to the releases which run on the host macOS version.
+func (q *Query) RunsOn(host OSVersion) *Query {
+	return q.with(func(xr *XcodeRelease) bool {
+		min, ok := q.ix.hosts[xr]
+		return ok && min.Compare(host) <= 0
+	})
+}
+
+// RunnableOn returns the releases which run on the host macOS version, ordered from the newest to the oldest.
+func (ix *Index) RunnableOn(host OSVersion) []*XcodeRelease {
+	return ix.Query().RunsOn(host).All()
+}
+
+// NewestFor returns the newest release in any of chs which runs on the host macOS version,
+// or nil if there is none. If chs is empty, only the final releases are considered.
+func (ix *Index) NewestFor(host OSVersion, chs ...Channel) *XcodeRelease {
+	if len(chs) == 0 {
+		chs = []Channel{ChannelRelease}
+	}
+
+	return ix.Query().Channel(chs...).RunsOn(host).First()
+}
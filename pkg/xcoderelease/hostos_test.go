@@ -0,0 +1,154 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseOSVersion(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    OSVersion
+		str     string
+		wantErr bool
+	}{
+		{in: "11.3", want: OSVersion{Major: 11, Minor: 3}, str: "11.3"},
+		{in: "10.15.4", want: OSVersion{Major: 10, Minor: 15, Patch: 4}, str: "10.15.4"},
+		{in: " 12.0 ", want: OSVersion{Major: 12}, str: "12.0"},
+		{in: "12", want: OSVersion{Major: 12}, str: "12.0"},
+		{in: "", wantErr: true},
+		{in: "11.x", wantErr: true},
+		{in: "10.15.4.1", wantErr: true},
+		{in: "macOS 11.3", wantErr: true},
+		{in: "11.-3", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseOSVersion(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseOSVersion(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseOSVersion(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+		if !tt.wantErr && got.String() != tt.str {
+			t.Errorf("ParseOSVersion(%q).String() = %q, want %q", tt.in, got.String(), tt.str)
+		}
+	}
+}
+
+func TestOSVersionCompare(t *testing.T) {
+	// ordered from the oldest
+	ordered := []string{"10.9", "10.14.6", "10.15", "10.15.4", "11.0", "11.3", "12.0.1", "12.3"}
+
+	for i := range ordered {
+		for j := range ordered {
+			vi, err := ParseOSVersion(ordered[i])
+			if err != nil {
+				t.Fatal(err)
+			}
+			vj, err := ParseOSVersion(ordered[j])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := vi.Compare(vj), compareInts(i, j); got != want {
+				t.Errorf("%s.Compare(%s) = %d, want %d", ordered[i], ordered[j], got, want)
+			}
+			if got, want := vi.Less(vj), i < j; got != want {
+				t.Errorf("%s.Less(%s) = %v, want %v", ordered[i], ordered[j], got, want)
+			}
+		}
+	}
+}
+
+func TestMinHostOS(t *testing.T) {
+	xr := testRelease(t, "13.2.1 (13C100)")
+	xr.Requires = "11.3"
+	if got, err := xr.MinHostOS(); err != nil || got != (OSVersion{Major: 11, Minor: 3}) {
+		t.Errorf("MinHostOS() = %v, %v, want 11.3", got, err)
+	}
+
+	xr.Requires = ""
+	if _, err := xr.MinHostOS(); err == nil {
+		t.Error("MinHostOS() error = nil for the empty Requires")
+	}
+}
+
+func TestRunnableOn(t *testing.T) {
+	xrs := testIndexReleases(t)
+	noRequires := testRelease(t, "11.0 (11A420a)")
+	xrs = append(xrs, noRequires)
+	ix := NewIndex(xrs)
+
+	tests := []struct {
+		host string
+		want []string
+	}{
+		{host: "10.15.3", want: nil},
+		{host: "10.15.4", want: []string{"12D4e"}},
+		{host: "11.2.3", want: []string{"12E507", "12D4e"}},
+		{host: "11.3", want: []string{"13C100", "13C90", "13C90", "13A1030d", "12E507", "12D4e"}},
+		{host: "12.2", want: []string{"13E5086k", "13C100", "13C90", "13C90", "13A1030d", "12E507", "12D4e"}},
+		{host: "12.3", want: []string{"14A5228q", "13E5086k", "13C100", "13C90", "13C90", "13A1030d", "12E507", "12D4e"}},
+	}
+	for _, tt := range tests {
+		host, err := ParseOSVersion(tt.host)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := builds(ix.RunnableOn(host)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("RunnableOn(%s) = %v, want %v", tt.host, got, tt.want)
+		}
+
+		// the Filter agrees with the indexed Query
+		var filtered []*XcodeRelease
+		for _, xr := range ix.Releases() {
+			if RunsOn(host)(xr) {
+				filtered = append(filtered, xr)
+			}
+		}
+		if got := builds(filtered); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("RunsOn(%s) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+
+	// the release without Requires never runs
+	if RunsOn(OSVersion{Major: 99})(noRequires) {
+		t.Error("RunsOn(99.0) matched the release without Requires")
+	}
+}
+
+func TestNewestFor(t *testing.T) {
+	ix := NewIndex(testIndexReleases(t))
+
+	tests := []struct {
+		host string
+		chs  []Channel
+		want string
+	}{
+		{host: "10.15", want: ""},
+		{host: "10.15.4", want: "12D4e"},
+		{host: "11.0", want: "12E507"},
+		{host: "11.3", want: "13C100"},
+		{host: "12.3", want: "13C100"},
+		{host: "12.3", chs: []Channel{ChannelRelease, ChannelBeta}, want: "14A5228q"},
+		{host: "12.2", chs: []Channel{ChannelBeta}, want: "13E5086k"},
+		{host: "11.3", chs: []Channel{ChannelBeta}, want: ""},
+	}
+	for _, tt := range tests {
+		host, err := ParseOSVersion(tt.host)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := ""
+		if xr := ix.NewestFor(host, tt.chs...); xr != nil {
+			got = xr.Version.Build
+		}
+		if got != tt.want {
+			t.Errorf("NewestFor(%s, %v) = %q, want %q", tt.host, tt.chs, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Installation represents an Xcode.app bundle on disk.
+type Installation struct {
+	// Path is the path of the Xcode.app directory.
+	Path string `json:"path"`
+
+	// Version is the version number, e.g. "13.2.1".
+	Version string `json:"version"`
+
+	// Build is the build number, e.g. "13C100".
+	Build string `json:"build"`
+
+	// SDKs is the SDKs found under Contents/Developer/Platforms, ordered by the platform directory and the version.
+	SDKs []InstalledSDK `json:"sdks"`
+}
+
+// InstalledSDK represents a SDK directory of an installation.
+type InstalledSDK struct {
+	// Platform is the platform of the SDK. The platforms not known to this package are named after the directory,
+	// e.g. "DriverKit" for DriverKit.platform.
+	Platform Platform `json:"platform"`
+
+	// Simulator reports whether the SDK is the one of the simulator.
+	Simulator bool `json:"simulator"`
+
+	// Version is the SDK version number, e.g. "15.2", or empty if it is unknown.
+	Version string `json:"version"`
+
+	// Path is the path of the .sdk directory, or of a symbolic link to it if the directory is outside
+	// the SDKs directory.
+	Path string `json:"path"`
+}
+
+// platformDirs maps the platform directory names under Contents/Developer/Platforms to the platforms.
+var platformDirs = map[string]struct {
+	platform  Platform
+	simulator bool
+}{
+	"MacOSX":           {PlatformMacOS, false},
+	"iPhoneOS":         {PlatformIOS, false},
+	"iPhoneSimulator":  {PlatformIOS, true},
+	"AppleTVOS":        {PlatformTvOS, false},
+	"AppleTVSimulator": {PlatformTvOS, true},
+	"WatchOS":          {PlatformWatchOS, false},
+	"WatchSimulator":   {PlatformWatchOS, true},
+}
+
+// ReadInstallation reads the Xcode.app bundle at path.
+//
+// The version and build are read from Contents/version.plist, falling back to Contents/Info.plist.
+// Only the XML property lists are supported, and path is not required to be on macOS.
+func ReadInstallation(path string) (*Installation, error) {
+	inst := &Installation{Path: path}
+	contents := filepath.Join(path, "Contents")
+
+	var errs []string
+	if dict, err := readPlistFile(filepath.Join(contents, "version.plist")); err == nil {
+		inst.Version = plistString(dict, "CFBundleShortVersionString")
+		inst.Build = plistString(dict, "ProductBuildVersion")
+	} else {
+		errs = append(errs, err.Error())
+	}
+	if inst.Version == "" || inst.Build == "" {
+		if dict, err := readPlistFile(filepath.Join(contents, "Info.plist")); err == nil {
+			if inst.Version == "" {
+				inst.Version = plistString(dict, "CFBundleShortVersionString")
+			}
+			if inst.Build == "" {
+				inst.Build = plistString(dict, "DTXcodeBuild")
+			}
+		} else {
+			errs = append(errs, err.Error())
+		}
+	}
+	if inst.Build == "" {
+		if len(errs) == 0 {
+			errs = append(errs, "no build number")
+		}
+		return nil, fmt.Errorf("read Xcode at %s: %s", path, strings.Join(errs, "; "))
+	}
+
+	sdks, err := readInstalledSDKs(filepath.Join(contents, "Developer", "Platforms"))
+	if err != nil {
+		return nil, fmt.Errorf("read Xcode at %s: %w", path, err)
+	}
+	inst.SDKs = sdks
+
+	return inst, nil
+}
+
+// readInstalledSDKs returns the SDKs under the Platforms directory dir.
+//
+// The SDK directories and the symbolic links to them, such as iPhoneOS15.2.sdk linking to iPhoneOS.sdk,
+// are listed once. The version is read from the versioned name, falling back to SDKSettings.plist.
+func readInstalledSDKs(dir string) ([]InstalledSDK, error) {
+	platforms, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var sdks []InstalledSDK
+	for _, pe := range platforms {
+		name := strings.TrimSuffix(pe.Name(), ".platform")
+		if name == pe.Name() {
+			continue
+		}
+		pd, ok := platformDirs[name]
+		if !ok {
+			pd.platform = Platform(name)
+		}
+
+		sdkDir := filepath.Join(dir, pe.Name(), "Developer", "SDKs")
+		entries, err := os.ReadDir(sdkDir)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var (
+			found []InstalledSDK
+			real  []string
+			index = make(map[string]int) // real path to the index in found
+		)
+		for _, se := range entries {
+			if !strings.HasSuffix(se.Name(), ".sdk") {
+				continue
+			}
+			path := filepath.Join(sdkDir, se.Name())
+			rp, err := filepath.EvalSymlinks(path)
+			if err != nil {
+				continue // dangling link
+			}
+			symlink := se.Type()&os.ModeSymlink != 0
+
+			i, ok := index[rp]
+			if !ok {
+				i = len(found)
+				index[rp] = i
+				found = append(found, InstalledSDK{Platform: pd.platform, Simulator: pd.simulator, Path: path})
+				real = append(real, rp)
+			}
+			sdk := &found[i]
+			if !symlink {
+				sdk.Path = path
+			}
+			if sdk.Version == "" {
+				sdk.Version = sdkDirVersion(se.Name())
+			}
+		}
+		for i := range found {
+			if found[i].Version != "" {
+				continue
+			}
+			if dict, err := readPlistFile(filepath.Join(real[i], "SDKSettings.plist")); err == nil {
+				found[i].Version = plistString(dict, "Version")
+			}
+		}
+		sort.SliceStable(found, func(i, j int) bool {
+			return compareDotted(found[i].Version, found[j].Version) < 0
+		})
+		sdks = append(sdks, found...)
+	}
+
+	return sdks, nil
+}
+
+// sdkDirVersion returns the version in the SDK directory name, e.g. "15.2" for "iPhoneOS15.2.sdk".
+func sdkDirVersion(name string) string {
+	name = strings.TrimSuffix(name, ".sdk")
+	for i := 0; i < len(name); i++ {
+		if isDigit(name[i]) {
+			return name[i:]
+		}
+	}
+
+	return ""
+}
+
+// FindInstalled reads the Xcode.app bundle at path and returns the release of its build number.
+//
+// It returns the installation along with a *ResolveError if no release has the build, e.g. if the data is
+// older than the installation.
+func (ix *Index) FindInstalled(path string) (*XcodeRelease, *Installation, error) {
+	inst, err := ReadInstallation(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	xr := ix.FindByBuild(inst.Build)
+	if xr == nil {
+		return nil, inst, &ResolveError{Spec: inst.Build, Nearest: ix.nearestBuild(inst.Build)}
+	}
+
+	return xr, inst, nil
+}
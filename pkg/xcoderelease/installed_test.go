@@ -0,0 +1,74 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadInstallation(t *testing.T) {
+	app := filepath.Join("testdata", "Xcode.app")
+	inst, err := ReadInstallation(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inst.Path != app || inst.Version != "13.2.1" || inst.Build != "13C100" {
+		t.Errorf("ReadInstallation() = %s %s %s", inst.Path, inst.Version, inst.Build)
+	}
+
+	sdk := func(dir, name string) string {
+		return filepath.Join(app, "Contents", "Developer", "Platforms", dir+".platform", "Developer", "SDKs", name)
+	}
+	want := []InstalledSDK{
+		{Platform: "DriverKit", Version: "21.2", Path: sdk("DriverKit", "DriverKit21.2.sdk")},
+		// the unversioned link to the versioned directory
+		{Platform: PlatformMacOS, Version: "10.15", Path: sdk("MacOSX", "MacOSX10.15.sdk")},
+		// the versioned link to the unversioned directory; the dangling WatchOS link is skipped
+		{Platform: PlatformIOS, Version: "15.2", Path: sdk("iPhoneOS", "iPhoneOS.sdk")},
+		// the version from SDKSettings.plist
+		{Platform: PlatformIOS, Simulator: true, Version: "15.2", Path: sdk("iPhoneSimulator", "iPhoneSimulator.sdk")},
+	}
+	if !reflect.DeepEqual(inst.SDKs, want) {
+		t.Errorf("ReadInstallation().SDKs =\n%+v\nwant\n%+v", inst.SDKs, want)
+	}
+}
+
+func TestReadInstallationInfoPlist(t *testing.T) {
+	inst, err := ReadInstallation(filepath.Join("testdata", "XcodeInfo.app"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inst.Version != "13.2" || inst.Build != "13C90" || inst.SDKs != nil {
+		t.Errorf("ReadInstallation() = %+v", inst)
+	}
+}
+
+func TestReadInstallationMissing(t *testing.T) {
+	if _, err := ReadInstallation(filepath.Join("testdata", "Missing.app")); err == nil {
+		t.Error("ReadInstallation() error = nil")
+	}
+}
+
+func TestFindInstalled(t *testing.T) {
+	ix := NewIndex(testReleases(t, "13.2.1 (13C100)", "13.2 (13C90)"))
+
+	xr, inst, err := ix.FindInstalled(filepath.Join("testdata", "Xcode.app"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if xr.Version.Build != "13C100" || inst.Build != "13C100" {
+		t.Errorf("FindInstalled() = %s, %s", xr.Version.Build, inst.Build)
+	}
+
+	ix = NewIndex(testReleases(t, "13.2 (13C90)"))
+	xr, inst, err = ix.FindInstalled(filepath.Join("testdata", "Xcode.app"))
+
+	var rerr *ResolveError
+	if !errors.As(err, &rerr) || rerr.Spec != "13C100" || xr != nil || inst == nil {
+		t.Errorf("FindInstalled() = %v, %v, %v, want the installation and *ResolveError", xr, inst, err)
+	}
+}
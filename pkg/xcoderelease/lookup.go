@@ -0,0 +1,169 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"sort"
+)
+
+// Compiler represents a kind of the compilers bundled with Xcode.
+type Compiler string
+
+// List of compilers.
+const (
+	CompilerClang   Compiler = "clang"
+	CompilerGCC     Compiler = "gcc"
+	CompilerLLVM    Compiler = "llvm"
+	CompilerLLVMGCC Compiler = "llvm_gcc"
+	CompilerSwift   Compiler = "swift"
+)
+
+// numbers returns the version numbers of the compiler kind.
+func (c *Compilers) numbers(kind Compiler) []string {
+	if c == nil {
+		return nil
+	}
+
+	var ns []string
+	switch kind {
+	case CompilerClang:
+		for _, e := range c.Clang {
+			ns = append(ns, e.Number)
+		}
+	case CompilerGCC:
+		for _, e := range c.Gcc {
+			ns = append(ns, e.Number)
+		}
+	case CompilerLLVM:
+		for _, e := range c.Llvm {
+			ns = append(ns, e.Number)
+		}
+	case CompilerLLVMGCC:
+		for _, e := range c.LlvmGcc {
+			ns = append(ns, e.Number)
+		}
+	case CompilerSwift:
+		for _, e := range c.Swift {
+			ns = append(ns, e.Number)
+		}
+	}
+
+	return ns
+}
+
+// Lookup is the result of a reverse lookup.
+type Lookup struct {
+	// All is the matched releases ordered from the oldest to the newest.
+	All []*XcodeRelease
+}
+
+// First returns the oldest matched release, or nil if there is none.
+func (l Lookup) First() *XcodeRelease {
+	if len(l.All) == 0 {
+		return nil
+	}
+
+	return l.All[0]
+}
+
+// Last returns the newest matched release, or nil if there is none.
+func (l Lookup) Last() *XcodeRelease {
+	if len(l.All) == 0 {
+		return nil
+	}
+
+	return l.All[len(l.All)-1]
+}
+
+// In returns the Lookup narrowed to the releases in any of chs.
+func (l Lookup) In(chs ...Channel) Lookup {
+	var nl Lookup
+
+	f := InChannel(chs...)
+	for _, xr := range l.All {
+		if f(xr) {
+			nl.All = append(nl.All, xr)
+		}
+	}
+
+	return nl
+}
+
+// reverseIndex maps the version numbers to the releases which contain them.
+type reverseIndex map[string][]*XcodeRelease
+
+func (ri reverseIndex) add(number string, xr *XcodeRelease) {
+	xrs := ri[number]
+	if len(xrs) > 0 && xrs[len(xrs)-1] == xr {
+		return // same version listed twice in a release
+	}
+	ri[number] = append(xrs, xr)
+}
+
+// indexLookups builds the reverse indexes of the SDKs and compilers.
+func (ix *Index) indexLookups() {
+	ix.pos = make(map[*XcodeRelease]int, len(ix.releases))
+	ix.bySDK = make(map[Platform]reverseIndex)
+	ix.byCompiler = make(map[Compiler]reverseIndex)
+
+	kinds := []Compiler{CompilerClang, CompilerGCC, CompilerLLVM, CompilerLLVMGCC, CompilerSwift}
+	for i, xr := range ix.releases {
+		ix.pos[xr] = i
+
+		for _, platform := range xr.SDKs.Platforms() {
+			for _, e := range xr.SDKs.For(platform) {
+				if ix.bySDK[platform] == nil {
+					ix.bySDK[platform] = make(reverseIndex)
+				}
+				ix.bySDK[platform].add(e.Number, xr)
+			}
+		}
+
+		for _, kind := range kinds {
+			for _, n := range xr.Compilers.numbers(kind) {
+				if ix.byCompiler[kind] == nil {
+					ix.byCompiler[kind] = make(reverseIndex)
+				}
+				ix.byCompiler[kind].add(n, xr)
+			}
+		}
+	}
+}
+
+// lookup returns the releases in ri whose version number matches version.
+func (ix *Index) lookup(ri reverseIndex, version string) Lookup {
+	seen := make(map[*XcodeRelease]bool)
+
+	var l Lookup
+	for n, xrs := range ri {
+		if !matchVersion(version, n) {
+			continue
+		}
+		for _, xr := range xrs {
+			if !seen[xr] {
+				seen[xr] = true
+				l.All = append(l.All, xr)
+			}
+		}
+	}
+	sort.Slice(l.All, func(i, j int) bool {
+		return ix.pos[l.All[i]] > ix.pos[l.All[j]]
+	})
+
+	return l
+}
+
+// WithSDK returns the releases which bundle the SDK of platform and version.
+//
+// The version matches the SDK versions it is a prefix of, e.g. "12.1" matches "12.1" and "12.1.1".
+func (ix *Index) WithSDK(platform Platform, version string) Lookup {
+	return ix.lookup(ix.bySDK[platform], version)
+}
+
+// WithCompiler returns the releases which bundle the compiler kind of version.
+//
+// The version matches the compiler versions it is a prefix of, e.g. "5.5" matches "5.5" and "5.5.2".
+func (ix *Index) WithCompiler(kind Compiler, version string) Lookup {
+	return ix.lookup(ix.byCompiler[kind], version)
+}
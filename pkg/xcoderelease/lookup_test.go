@@ -0,0 +1,113 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWithSDK(t *testing.T) {
+	ix := NewIndex(testIndexReleases(t))
+
+	tests := []struct {
+		platform Platform
+		version  string
+		want     []string // oldest first
+	}{
+		{PlatformMacOS, "12.1", []string{"13C90", "13C90", "13C100"}},
+		{PlatformMacOS, "12.1.0", []string{"13C90", "13C90", "13C100"}},
+		{PlatformMacOS, "12", []string{"13A1030d", "13C90", "13C90", "13C100", "13E5086k"}},
+		{PlatformMacOS, "11.3", []string{"12E507"}},
+		{PlatformIOS, "15.2", []string{"13C90", "13C90", "13C100"}},
+		{ParsePlatform("macos"), "13.0", []string{"14A5228q"}},
+		{ParsePlatform("IOS"), "14", []string{"12D4e", "12E507"}},
+
+		// not found
+		{PlatformMacOS, "12.10", nil},
+		{PlatformMacOS, "10.15", nil},
+		{PlatformTvOS, "15.2", nil},
+		{Platform("visionOS"), "1.0", nil},
+	}
+	for _, tt := range tests {
+		l := ix.WithSDK(tt.platform, tt.version)
+		if got := builds(l.All); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("WithSDK(%s, %q) = %v, want %v", tt.platform, tt.version, got, tt.want)
+			continue
+		}
+		if len(tt.want) == 0 {
+			if l.First() != nil || l.Last() != nil {
+				t.Errorf("WithSDK(%s, %q) First, Last = %v, %v, want nil", tt.platform, tt.version, l.First(), l.Last())
+			}
+			continue
+		}
+		if l.First() != l.All[0] || l.Last() != l.All[len(l.All)-1] {
+			t.Errorf("WithSDK(%s, %q) First, Last = %v, %v", tt.platform, tt.version, l.First(), l.Last())
+		}
+	}
+}
+
+func TestWithCompiler(t *testing.T) {
+	ix := NewIndex(testIndexReleases(t))
+
+	tests := []struct {
+		kind    Compiler
+		version string
+		want    []string // oldest first
+	}{
+		{CompilerSwift, "5.5", []string{"13A1030d", "13C90", "13C90", "13C100"}},
+		{CompilerSwift, "5.5.2", []string{"13C90", "13C90", "13C100"}},
+		{CompilerSwift, "5.4.2", []string{"12E507"}},
+		{CompilerClang, "13.0.0", []string{"13A1030d", "13C90", "13C90", "13C100"}},
+		{CompilerClang, "13", []string{"13A1030d", "13C90", "13C90", "13C100", "13E5086k"}},
+
+		// not found
+		{CompilerSwift, "5.50", nil},
+		{CompilerSwift, "6", nil},
+		{CompilerGCC, "4.2", nil},
+	}
+	for _, tt := range tests {
+		if got := builds(ix.WithCompiler(tt.kind, tt.version).All); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("WithCompiler(%s, %q) = %v, want %v", tt.kind, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestLookupAmbiguous(t *testing.T) {
+	ix := NewIndex(testIndexReleases(t))
+
+	// 13C90 is both the 13.2 RC and the final 13.2
+	l := ix.WithSDK(PlatformMacOS, "12.1")
+	if first := l.First(); first.Version.Channel() != ChannelRC {
+		t.Errorf("First() = %s, want the RC", releaseTitle(first))
+	}
+	if last := l.Last(); last.Version.Build != "13C100" {
+		t.Errorf("Last() = %s, want 13.2.1", releaseTitle(last))
+	}
+
+	final := l.In(ChannelRelease)
+	if got := builds(final.All); !reflect.DeepEqual(got, []string{"13C90", "13C100"}) {
+		t.Errorf("In(release) = %v", got)
+	}
+	if first := final.First(); first.Version.Channel() != ChannelRelease || first.Version.Build != "13C90" {
+		t.Errorf("In(release).First() = %s, want the final 13.2", releaseTitle(first))
+	}
+	if got := l.In(ChannelBeta).All; got != nil {
+		t.Errorf("In(beta) = %v, want nil", builds(got))
+	}
+}
+
+func TestLookupDuplicateVersion(t *testing.T) {
+	xr := testRelease(t, "13.2.1 (13C100)")
+	xr.SDKs = &SDKs{MacOS: []SDK{{Number: "12.1"}, {Number: "12.1"}}}
+	xr.Compilers = &Compilers{Swift: []SwiftCompiler{{Number: "5.5.2"}, {Number: "5.5"}}}
+	ix := NewIndex([]*XcodeRelease{xr})
+
+	if got := ix.WithSDK(PlatformMacOS, "12.1").All; len(got) != 1 {
+		t.Errorf("WithSDK() = %v, want the release once", builds(got))
+	}
+	if got := ix.WithCompiler(CompilerSwift, "5.5").All; len(got) != 1 {
+		t.Errorf("WithCompiler() = %v, want the release once", builds(got))
+	}
+}
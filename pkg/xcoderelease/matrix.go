@@ -0,0 +1,257 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MatrixPolicy selects the releases of a CI build matrix.
+type MatrixPolicy struct {
+	// Majors is the number of the newest major versions whose latest final release is selected.
+	Majors int
+
+	// Prerelease selects the newest prerelease, such as a beta or a RC, if it is newer than all the final releases.
+	Prerelease bool
+
+	// Host, if not zero, limits the selection to the releases which run on the host macOS version.
+	Host OSVersion
+
+	// Include is the specifiers accepted by Index.Resolve of the releases always selected.
+	Include []string
+}
+
+// MatrixEntry is an entry of a CI build matrix.
+type MatrixEntry struct {
+	// Xcode is the version number, e.g. "13.2".
+	Xcode string `json:"xcode"`
+	// Version is the human readable version with the channel, e.g. "13.2 Beta 2".
+	Version    string `json:"version"`
+	Build      string `json:"build"`
+	Channel    string `json:"channel"`
+	Prerelease bool   `json:"prerelease"`
+
+	// The SDK version numbers of each platform, or empty if the release has no SDK of the platform.
+	MacOSSDK   string `json:"macos_sdk,omitempty"`
+	IOSSDK     string `json:"ios_sdk,omitempty"`
+	TvOSSDK    string `json:"tvos_sdk,omitempty"`
+	WatchOSSDK string `json:"watchos_sdk,omitempty"`
+
+	// Release is the selected release.
+	Release *XcodeRelease `json:"-"`
+}
+
+// NewMatrixEntry returns the MatrixEntry of xr.
+func NewMatrixEntry(xr *XcodeRelease) MatrixEntry {
+	v, _ := xr.Version.XcodeVersion()
+
+	return MatrixEntry{
+		Xcode:      xr.Version.Number,
+		Version:    xr.Title(),
+		Build:      xr.Version.Build,
+		Channel:    v.Channel.String(),
+		Prerelease: v.IsPrerelease(),
+		MacOSSDK:   firstSDK(xr, PlatformMacOS),
+		IOSSDK:     firstSDK(xr, PlatformIOS),
+		TvOSSDK:    firstSDK(xr, PlatformTvOS),
+		WatchOSSDK: firstSDK(xr, PlatformWatchOS),
+		Release:    xr,
+	}
+}
+
+func firstSDK(xr *XcodeRelease, p Platform) string {
+	if sdks := xr.SDKs.For(p); len(sdks) > 0 {
+		return sdks[0].Number
+	}
+
+	return ""
+}
+
+// Matrix returns the entries of the releases selected by p, ordered from the newest to the oldest.
+//
+// A release selected by several rules of p appears once. It returns a *ResolveError if a specifier in p.Include matches no release.
+func (ix *Index) Matrix(p MatrixPolicy) ([]MatrixEntry, error) {
+	selected := make(map[*XcodeRelease]bool)
+
+	q := ix.Query()
+	if p.Host != (OSVersion{}) {
+		q = q.RunsOn(p.Host)
+	}
+
+	if p.Prerelease {
+		if xr := q.First(); xr != nil && ix.versions[xr].IsPrerelease() {
+			selected[xr] = true
+		}
+	}
+
+	if p.Majors > 0 {
+		n, major := 0, -1
+		for _, xr := range q.Channel(ChannelRelease).All() {
+			v := ix.versions[xr]
+			if v.Major == major {
+				continue
+			}
+			if n == p.Majors {
+				break
+			}
+			selected[xr] = true
+			major = v.Major
+			n++
+		}
+	}
+
+	for _, spec := range p.Include {
+		xr, err := ix.Resolve(spec)
+		if err != nil {
+			return nil, err
+		}
+		selected[xr] = true
+	}
+
+	entries := make([]MatrixEntry, 0, len(selected))
+	for _, xr := range ix.releases {
+		if selected[xr] {
+			entries = append(entries, NewMatrixEntry(xr))
+		}
+	}
+
+	return entries, nil
+}
+
+// MatrixFormat represents an output format of the CI build matrix.
+type MatrixFormat string
+
+// List of matrix formats.
+const (
+	// MatrixGitHub is the strategy of a GitHub Actions job.
+	MatrixGitHub MatrixFormat = "github"
+	// MatrixBuildkite is a Buildkite pipeline fragment with a step per entry.
+	MatrixBuildkite MatrixFormat = "buildkite"
+	// MatrixJSON is the JSON array of the entries.
+	MatrixJSON MatrixFormat = "json"
+)
+
+// ParseMatrixFormat parses the matrix format name s case-insensitively.
+func ParseMatrixFormat(s string) (MatrixFormat, error) {
+	switch f := MatrixFormat(strings.ToLower(s)); f {
+	case MatrixGitHub, MatrixBuildkite, MatrixJSON:
+		return f, nil
+	case "github-actions", "gha":
+		return MatrixGitHub, nil
+	default:
+		return "", fmt.Errorf("unknown matrix format %q", s)
+	}
+}
+
+// EncodeMatrix writes entries to w in the format f.
+//
+// MatrixBuildkite is not supported since the steps need a command. Use EncodeBuildkiteSteps instead.
+func EncodeMatrix(w io.Writer, f MatrixFormat, entries []MatrixEntry) error {
+	switch f {
+	case MatrixGitHub:
+		return EncodeGitHubMatrix(w, entries)
+	case MatrixBuildkite:
+		return fmt.Errorf("matrix format %q needs a command: use EncodeBuildkiteSteps", f)
+	case MatrixJSON:
+		if entries == nil {
+			entries = []MatrixEntry{}
+		}
+		data, err := marshalJSON(entries)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append(data, '\n'))
+		return err
+	default:
+		return fmt.Errorf("unknown matrix format %q", f)
+	}
+}
+
+type githubStrategy struct {
+	Strategy struct {
+		FailFast bool `json:"fail-fast"`
+		Matrix   struct {
+			Include []MatrixEntry `json:"include"`
+		} `json:"matrix"`
+	} `json:"strategy"`
+}
+
+// EncodeGitHubMatrix writes entries to w as the strategy of a GitHub Actions job, with an include per entry.
+//
+// The fields of an entry are available as the matrix context, e.g. ${{ matrix.xcode }}.
+func EncodeGitHubMatrix(w io.Writer, entries []MatrixEntry) error {
+	var s githubStrategy
+	s.Strategy.Matrix.Include = entries
+	if entries == nil {
+		s.Strategy.Matrix.Include = []MatrixEntry{}
+	}
+
+	data, err := MarshalYAML(&s)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+	return err
+}
+
+type buildkiteEnv struct {
+	XcodeVersion string `json:"XCODE_VERSION"`
+	XcodeBuild   string `json:"XCODE_BUILD"`
+	MacOSSDK     string `json:"MACOS_SDK,omitempty"`
+	IOSSDK       string `json:"IOS_SDK,omitempty"`
+	TvOSSDK      string `json:"TVOS_SDK,omitempty"`
+	WatchOSSDK   string `json:"WATCHOS_SDK,omitempty"`
+}
+
+type buildkiteStep struct {
+	Label   string       `json:"label"`
+	Command string       `json:"command"`
+	Env     buildkiteEnv `json:"env"`
+	Agents  struct {
+		Xcode string `json:"xcode"`
+	} `json:"agents"`
+	SoftFail bool `json:"soft_fail,omitempty"`
+}
+
+// EncodeBuildkiteSteps writes entries to w as a Buildkite pipeline fragment with a step per entry.
+//
+// Each step runs command with the XCODE_VERSION, XCODE_BUILD and the SDK version environment variables,
+// and targets the agents tagged with the Xcode version. The steps of the prereleases are allowed to fail.
+// It returns an error if command is empty, since Buildkite rejects the steps without a command.
+func EncodeBuildkiteSteps(w io.Writer, entries []MatrixEntry, command string) error {
+	if command == "" {
+		return fmt.Errorf("encode buildkite steps: empty command")
+	}
+
+	var pipeline struct {
+		Steps []buildkiteStep `json:"steps"`
+	}
+	pipeline.Steps = make([]buildkiteStep, len(entries))
+	for i, e := range entries {
+		s := &pipeline.Steps[i]
+		s.Label = ":xcode: Xcode " + e.Version
+		s.Command = command
+		s.Env = buildkiteEnv{
+			XcodeVersion: e.Xcode,
+			XcodeBuild:   e.Build,
+			MacOSSDK:     e.MacOSSDK,
+			IOSSDK:       e.IOSSDK,
+			TvOSSDK:      e.TvOSSDK,
+			WatchOSSDK:   e.WatchOSSDK,
+		}
+		s.Agents.Xcode = e.Xcode
+		s.SoftFail = e.Prerelease
+	}
+
+	data, err := MarshalYAML(&pipeline)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+	return err
+}
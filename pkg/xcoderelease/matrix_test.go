@@ -0,0 +1,114 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMatrix(t *testing.T) {
+	ix := NewIndex(testIndexReleases(t))
+
+	tests := []struct {
+		name   string
+		policy MatrixPolicy
+		want   []string
+	}{
+		{name: "Empty", policy: MatrixPolicy{}, want: []string{}},
+		{name: "Majors", policy: MatrixPolicy{Majors: 2}, want: []string{"13C100", "12E507"}},
+		{name: "MajorsMoreThanKnown", policy: MatrixPolicy{Majors: 5}, want: []string{"13C100", "12E507"}},
+		{name: "Prerelease", policy: MatrixPolicy{Prerelease: true}, want: []string{"14A5228q"}},
+		{name: "MajorsAndPrerelease", policy: MatrixPolicy{Majors: 1, Prerelease: true}, want: []string{"14A5228q", "13C100"}},
+		{
+			name:   "Host",
+			policy: MatrixPolicy{Majors: 2, Prerelease: true, Host: OSVersion{Major: 12, Minor: 2}},
+			want:   []string{"13E5086k", "13C100", "12E507"},
+		},
+		{
+			// the newest release on the host is final, so no prerelease is newer
+			name:   "HostNoNewerPrerelease",
+			policy: MatrixPolicy{Prerelease: true, Host: OSVersion{Major: 11, Minor: 3}},
+			want:   []string{},
+		},
+		{name: "HostOld", policy: MatrixPolicy{Majors: 2, Host: OSVersion{Major: 11}}, want: []string{"12E507"}},
+		{name: "Include", policy: MatrixPolicy{Majors: 1, Include: []string{"12.4", "13.1"}}, want: []string{"13C100", "13A1030d", "12D4e"}},
+		{name: "IncludeSelected", policy: MatrixPolicy{Majors: 1, Include: []string{"13.2.1", "latest-stable"}}, want: []string{"13C100"}},
+		{
+			// the included releases are selected regardless of the host
+			name:   "IncludeOtherHost",
+			policy: MatrixPolicy{Majors: 1, Host: OSVersion{Major: 11}, Include: []string{"13.2 RC"}},
+			want:   []string{"13C90", "12E507"},
+		},
+	}
+	for _, tt := range tests {
+		entries, err := ix.Matrix(tt.policy)
+		if err != nil {
+			t.Errorf("%s: Matrix() error = %v", tt.name, err)
+			continue
+		}
+		got := []string{}
+		for _, e := range entries {
+			got = append(got, e.Build)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Matrix() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMatrixIncludeNoMatch(t *testing.T) {
+	ix := NewIndex(testIndexReleases(t))
+
+	_, err := ix.Matrix(MatrixPolicy{Majors: 1, Include: []string{"11.0"}})
+
+	var rerr *ResolveError
+	if !errors.As(err, &rerr) || rerr.Spec != "11.0" {
+		t.Errorf("Matrix() error = %v, want *ResolveError of 11.0", err)
+	}
+}
+
+func TestNewMatrixEntry(t *testing.T) {
+	xr := testIndexReleases(t)[1]
+
+	want := MatrixEntry{
+		Xcode:      "13.3",
+		Version:    "13.3 Beta 1",
+		Build:      "13E5086k",
+		Channel:    "beta",
+		Prerelease: true,
+		MacOSSDK:   "12.3",
+		IOSSDK:     "15.4",
+		Release:    xr,
+	}
+	if got := NewMatrixEntry(xr); got != want {
+		t.Errorf("NewMatrixEntry() = %+v, want %+v", got, want)
+	}
+}
+
+func TestEncodeBuildkiteSteps(t *testing.T) {
+	entries := []MatrixEntry{{Xcode: "13.2.1", Version: "13.2.1", Build: "13C100"}}
+
+	var buf bytes.Buffer
+	if err := EncodeBuildkiteSteps(&buf, entries, "make test"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "make test") {
+		t.Errorf("EncodeBuildkiteSteps() =\n%s\nwant the command", buf.String())
+	}
+
+	buf.Reset()
+	if err := EncodeBuildkiteSteps(&buf, entries, ""); err == nil {
+		t.Error("EncodeBuildkiteSteps() with an empty command error = nil")
+	}
+	if err := EncodeMatrix(&buf, MatrixBuildkite, entries); err == nil {
+		t.Error("EncodeMatrix(MatrixBuildkite) error = nil")
+	}
+	if buf.Len() > 0 {
+		t.Errorf("wrote %q on the errors", buf.String())
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// errBinaryPlist is returned by readPlist for the binary property lists.
+var errBinaryPlist = errors.New("binary property list is not supported")
+
+// readPlistFile reads the XML property list file whose root is a dictionary.
+func readPlistFile(path string) (map[string]interface{}, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	dict, err := readPlist(f)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+
+	return dict, nil
+}
+
+// readPlist reads the XML property list whose root is a dictionary.
+//
+// The values are decoded as string for string, integer, real, date and data, bool for true and false,
+// []interface{} for array and map[string]interface{} for dict.
+func readPlist(r io.Reader) (map[string]interface{}, error) {
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(8); bytes.HasPrefix(magic, []byte("bplist")) {
+		return nil, errBinaryPlist
+	}
+
+	dec := xml.NewDecoder(br)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil, errors.New("no plist element")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if se, ok := tok.(xml.StartElement); ok {
+			if se.Name.Local != "plist" {
+				return nil, fmt.Errorf("unexpected root element <%s>", se.Name.Local)
+			}
+			break
+		}
+	}
+
+	v, err := readPlistValue(dec)
+	if err != nil {
+		return nil, err
+	}
+	dict, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("root value is %T, want dict", v)
+	}
+
+	return dict, nil
+}
+
+// readPlistValue reads the next value element of dec.
+func readPlistValue(dec *xml.Decoder) (interface{}, error) {
+	se, err := nextStart(dec)
+	if err != nil {
+		return nil, err
+	}
+
+	return readPlistElement(dec, se)
+}
+
+// nextStart returns the next start element of dec, or an error at an end element.
+func nextStart(dec *xml.Decoder) (xml.StartElement, error) {
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return xml.StartElement{}, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			return t, nil
+		case xml.EndElement:
+			return xml.StartElement{}, fmt.Errorf("unexpected </%s>", t.Name.Local)
+		}
+	}
+}
+
+func readPlistElement(dec *xml.Decoder, se xml.StartElement) (interface{}, error) {
+	switch se.Name.Local {
+	case "string", "integer", "real", "date", "data":
+		var s string
+		if err := dec.DecodeElement(&s, &se); err != nil {
+			return nil, err
+		}
+		if se.Name.Local != "string" {
+			s = strings.TrimSpace(s)
+		}
+		return s, nil
+
+	case "true", "false":
+		if err := dec.Skip(); err != nil {
+			return nil, err
+		}
+		return se.Name.Local == "true", nil
+
+	case "array":
+		a := []interface{}{}
+		for {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch t := tok.(type) {
+			case xml.StartElement:
+				v, err := readPlistElement(dec, t)
+				if err != nil {
+					return nil, err
+				}
+				a = append(a, v)
+			case xml.EndElement:
+				return a, nil
+			}
+		}
+
+	case "dict":
+		d := make(map[string]interface{})
+		for {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch t := tok.(type) {
+			case xml.StartElement:
+				if t.Name.Local != "key" {
+					return nil, fmt.Errorf("unexpected <%s> in dict, want <key>", t.Name.Local)
+				}
+				var key string
+				if err := dec.DecodeElement(&key, &t); err != nil {
+					return nil, err
+				}
+				v, err := readPlistValue(dec)
+				if err != nil {
+					return nil, fmt.Errorf("key %q: %w", key, err)
+				}
+				d[key] = v
+			case xml.EndElement:
+				return d, nil
+			}
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown element <%s>", se.Name.Local)
+	}
+}
+
+// plistString returns the string value of key in dict, or "" if it is absent or not a string.
+func plistString(dict map[string]interface{}, key string) string {
+	s, _ := dict[key].(string)
+	return s
+}
@@ -0,0 +1,390 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Index is the precomputed index of the releases for fast lookups.
+//
+// The Index must not be modified after creation, and is safe for concurrent use.
+type Index struct {
+	releases  []*XcodeRelease // newest first
+	versions  map[*XcodeRelease]XcodeVersion
+	hosts     map[*XcodeRelease]OSVersion
+	byBuild   map[string][]*XcodeRelease
+	byChannel map[Channel][]*XcodeRelease
+	bySHA1    map[string]*XcodeRelease
+
+	pos        map[*XcodeRelease]int
+	bySDK      map[Platform]reverseIndex
+	byCompiler map[Compiler]reverseIndex
+}
+
+// NewIndex returns the new Index of xrs.
+//
+// Releases whose version fails to parse are kept, ordered as the oldest ones.
+func NewIndex(xrs []*XcodeRelease) *Index {
+	ix := &Index{
+		releases:  make([]*XcodeRelease, 0, len(xrs)),
+		versions:  make(map[*XcodeRelease]XcodeVersion, len(xrs)),
+		hosts:     make(map[*XcodeRelease]OSVersion, len(xrs)),
+		byBuild:   make(map[string][]*XcodeRelease, len(xrs)),
+		byChannel: make(map[Channel][]*XcodeRelease),
+		bySHA1:    make(map[string]*XcodeRelease),
+	}
+
+	for _, xr := range xrs {
+		if xr == nil {
+			continue
+		}
+		v, _ := xr.Version.XcodeVersion()
+		ix.versions[xr] = v
+		if host, err := xr.MinHostOS(); err == nil {
+			ix.hosts[xr] = host
+		}
+		ix.releases = append(ix.releases, xr)
+	}
+	sort.SliceStable(ix.releases, func(i, j int) bool {
+		return ix.newer(ix.releases[i], ix.releases[j])
+	})
+
+	for _, xr := range ix.releases {
+		ix.byBuild[xr.Version.Build] = append(ix.byBuild[xr.Version.Build], xr)
+		ch := ix.versions[xr].Channel
+		ix.byChannel[ch] = append(ix.byChannel[ch], xr)
+		if sum := strings.ToLower(strings.TrimSpace(xr.Checksums.Sha1)); sum != "" {
+			if _, ok := ix.bySHA1[sum]; !ok {
+				ix.bySHA1[sum] = xr
+			}
+		}
+	}
+	ix.indexLookups()
+
+	return ix
+}
+
+// newer reports whether a is newer than b, comparing the versions and then the release dates.
+func (ix *Index) newer(a, b *XcodeRelease) bool {
+	if c := ix.versions[a].Compare(ix.versions[b]); c != 0 {
+		return c > 0
+	}
+
+	return b.Date.Before(a.Date)
+}
+
+// Releases returns all releases ordered from the newest to the oldest.
+//
+// The returned slice must not be modified.
+func (ix *Index) Releases() []*XcodeRelease {
+	return ix.releases
+}
+
+// Version returns the parsed version of xr, which must be in ix.
+func (ix *Index) Version(xr *XcodeRelease) XcodeVersion {
+	return ix.versions[xr]
+}
+
+// Latest returns the newest release in ch, or nil if there is none.
+func (ix *Index) Latest(ch Channel) *XcodeRelease {
+	if xrs := ix.byChannel[ch]; len(xrs) > 0 {
+		return xrs[0]
+	}
+
+	return nil
+}
+
+// LatestPerMajor returns the newest final release of each major version, ordered from the newest major.
+func (ix *Index) LatestPerMajor() []*XcodeRelease {
+	var xrs []*XcodeRelease
+
+	major := -1
+	for _, xr := range ix.byChannel[ChannelRelease] {
+		if v := ix.versions[xr]; v.Major != major {
+			major = v.Major
+			xrs = append(xrs, xr)
+		}
+	}
+
+	return xrs
+}
+
+// FindByBuild returns the release built as build, or nil if there is none.
+//
+// If several releases share the build, such as a RC promoted to the final release, the newest one is returned.
+func (ix *Index) FindByBuild(build string) *XcodeRelease {
+	if xrs := ix.byBuild[build]; len(xrs) > 0 {
+		return xrs[0]
+	}
+
+	return nil
+}
+
+// Query returns the new Query matching all releases in ix.
+func (ix *Index) Query() *Query {
+	return &Query{ix: ix}
+}
+
+// Filter reports whether xr matches a condition.
+type Filter func(xr *XcodeRelease) bool
+
+// And returns the Filter matching the releases matched by all fs.
+func And(fs ...Filter) Filter {
+	return func(xr *XcodeRelease) bool {
+		for _, f := range fs {
+			if !f(xr) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or returns the Filter matching the releases matched by any of fs.
+func Or(fs ...Filter) Filter {
+	return func(xr *XcodeRelease) bool {
+		for _, f := range fs {
+			if f(xr) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not returns the Filter matching the releases not matched by f.
+func Not(f Filter) Filter {
+	return func(xr *XcodeRelease) bool {
+		return !f(xr)
+	}
+}
+
+// InChannel returns the Filter matching the releases in any of chs.
+func InChannel(chs ...Channel) Filter {
+	return func(xr *XcodeRelease) bool {
+		ch := xr.Version.Channel()
+		for _, c := range chs {
+			if c == ch {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// VersionRange returns the Filter matching the releases whose version is in range [min, max).
+// A zero max has no upper bound.
+func VersionRange(min, max XcodeVersion) Filter {
+	return func(xr *XcodeRelease) bool {
+		v, err := xr.Version.XcodeVersion()
+		return err == nil && inVersionRange(v, min, max)
+	}
+}
+
+func inVersionRange(v, min, max XcodeVersion) bool {
+	return v.Compare(min) >= 0 && (max == XcodeVersion{} || v.Compare(max) < 0)
+}
+
+// DateRange returns the Filter matching the releases dated in range [from, to].
+// A zero from or to has no bound on that side.
+func DateRange(from, to time.Time) Filter {
+	return func(xr *XcodeRelease) bool {
+		t := xr.Date.Time()
+		return (from.IsZero() || !t.Before(from)) && (to.IsZero() || !t.After(to))
+	}
+}
+
+// HasSDK returns the Filter matching the releases which bundle the SDK of platform.
+//
+// The version matches the SDK versions it is a prefix of, e.g. "12" matches "12.1". An empty version matches any SDK version.
+func HasSDK(platform Platform, version string) Filter {
+	return func(xr *XcodeRelease) bool {
+		for _, s := range xr.SDKs.For(platform) {
+			if matchVersion(version, s.Number) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Query is the composable query over an Index.
+//
+// Each method returns the new Query and leaves the receiver unchanged.
+type Query struct {
+	ix       *Index
+	channels []Channel
+	filters  []Filter
+}
+
+func (q *Query) with(f Filter) *Query {
+	nq := *q
+	nq.filters = append(q.filters[:len(q.filters):len(q.filters)], f)
+	return &nq
+}
+
+// Where narrows q to the releases matched by all fs.
+func (q *Query) Where(fs ...Filter) *Query {
+	return q.with(And(fs...))
+}
+
+// Channel narrows q to the releases in any of chs.
+func (q *Query) Channel(chs ...Channel) *Query {
+	nq := *q
+	if len(q.channels) == 0 {
+		nq.channels = chs
+		return &nq
+	}
+
+	nq.channels = nil
+	for _, c := range q.channels {
+		for _, ch := range chs {
+			if c == ch {
+				nq.channels = append(nq.channels, c)
+			}
+		}
+	}
+	if len(nq.channels) == 0 {
+		return nq.with(func(*XcodeRelease) bool { return false })
+	}
+
+	return &nq
+}
+
+// VersionRange narrows q to the releases whose version is in range [min, max).
+// A zero max has no upper bound.
+func (q *Query) VersionRange(min, max XcodeVersion) *Query {
+	return q.with(func(xr *XcodeRelease) bool {
+		return inVersionRange(q.ix.versions[xr], min, max)
+	})
+}
+
+// DateRange narrows q to the releases dated in range [from, to].
+func (q *Query) DateRange(from, to time.Time) *Query {
+	return q.with(DateRange(from, to))
+}
+
+// HasSDK narrows q to the releases which bundle the SDK of platform and version.
+func (q *Query) HasSDK(platform Platform, version string) *Query {
+	return q.with(HasSDK(platform, version))
+}
+
+// candidates returns the releases to filter, newest first.
+func (q *Query) candidates() []*XcodeRelease {
+	if len(q.channels) == 0 {
+		return q.ix.releases
+	}
+	if len(q.channels) == 1 {
+		return q.ix.byChannel[q.channels[0]]
+	}
+
+	var xrs []*XcodeRelease
+	for _, ch := range q.channels {
+		xrs = append(xrs, q.ix.byChannel[ch]...)
+	}
+	sort.SliceStable(xrs, func(i, j int) bool {
+		return q.ix.newer(xrs[i], xrs[j])
+	})
+
+	return xrs
+}
+
+func (q *Query) match(xr *XcodeRelease) bool {
+	for _, f := range q.filters {
+		if !f(xr) {
+			return false
+		}
+	}
+	return true
+}
+
+// All returns the matched releases ordered from the newest to the oldest.
+func (q *Query) All() []*XcodeRelease {
+	var xrs []*XcodeRelease
+	for _, xr := range q.candidates() {
+		if q.match(xr) {
+			xrs = append(xrs, xr)
+		}
+	}
+
+	return xrs
+}
+
+// First returns the newest matched release, or nil if there is none.
+func (q *Query) First() *XcodeRelease {
+	for _, xr := range q.candidates() {
+		if q.match(xr) {
+			return xr
+		}
+	}
+
+	return nil
+}
+
+// Count returns the number of the matched releases.
+func (q *Query) Count() int {
+	n := 0
+	for _, xr := range q.candidates() {
+		if q.match(xr) {
+			n++
+		}
+	}
+
+	return n
+}
+
+// matchVersion reports whether the dotted version v starts with the components of pattern,
+// where missing components are zero, e.g. "12.1" matches "12.1" and "12.1.2" but not "12.10".
+// An empty pattern matches any version.
+func matchVersion(pattern, v string) bool {
+	if pattern == "" {
+		return true
+	}
+
+	ps, vs := strings.Split(pattern, "."), strings.Split(v, ".")
+	for i, p := range ps {
+		c := "0"
+		if i < len(vs) {
+			c = vs[i]
+		}
+		if compareDotted(p, c) != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// compareDotted compares the dotted versions a and b numerically, e.g. "10.15.4" < "11.0".
+// Missing components are zero, and non-numeric components compare as strings.
+func compareDotted(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		x, y := "0", "0"
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+
+		xn, xerr := strconv.Atoi(x)
+		yn, yerr := strconv.Atoi(y)
+		if xerr != nil || yerr != nil {
+			if c := strings.Compare(x, y); c != 0 {
+				return c
+			}
+			continue
+		}
+		if c := compareInts(xn, yn); c != 0 {
+			return c
+		}
+	}
+
+	return 0
+}
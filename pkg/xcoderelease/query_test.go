@@ -0,0 +1,258 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testIndexReleases returns the releases shaped like data.json, newest first.
+func testIndexReleases(t *testing.T) []*XcodeRelease {
+	t.Helper()
+
+	entries := []struct {
+		version  string
+		date     Date
+		requires string
+		macOS    string
+		iOS      string
+		swift    string
+		clang    string
+	}{
+		{"14.0 Beta 1 (14A5228q)", Date{Day: 6, Month: 6, Year: 2022}, "12.3", "13.0", "16.0", "5.7", "14.0.0"},
+		{"13.3 Beta 1 (13E5086k)", Date{Day: 27, Month: 1, Year: 2022}, "12.0", "12.3", "15.4", "5.6", "13.1.6"},
+		{"13.2.1 (13C100)", Date{Day: 17, Month: 12, Year: 2021}, "11.3", "12.1", "15.2", "5.5.2", "13.0.0"},
+		{"13.2 (13C90)", Date{Day: 13, Month: 12, Year: 2021}, "11.3", "12.1", "15.2", "5.5.2", "13.0.0"},
+		{"13.2 RC (13C90)", Date{Day: 7, Month: 12, Year: 2021}, "11.3", "12.1", "15.2", "5.5.2", "13.0.0"},
+		{"13.1 (13A1030d)", Date{Day: 25, Month: 10, Year: 2021}, "11.3", "12.0", "15.0", "5.5.1", "13.0.0"},
+		{"12.5.1 (12E507)", Date{Day: 21, Month: 6, Year: 2021}, "11.0", "11.3", "14.5", "5.4.2", "12.0.5"},
+		{"12.4 (12D4e)", Date{Day: 26, Month: 1, Year: 2021}, "10.15.4", "11.1", "14.4", "5.3.2", "12.0.0"},
+	}
+
+	xrs := make([]*XcodeRelease, len(entries))
+	for i, e := range entries {
+		xr := testRelease(t, e.version)
+		xr.Date = e.date
+		xr.Requires = e.requires
+		xr.SDKs = &SDKs{
+			MacOS: []SDK{{Number: e.macOS, Release: true}},
+			IOS:   []SDK{{Number: e.iOS, Release: true}},
+		}
+		xr.Compilers = &Compilers{
+			Clang: []ClangCompiler{{Number: e.clang, Release: true}},
+			Swift: []SwiftCompiler{{Number: e.swift, Release: true}},
+		}
+		xrs[i] = xr
+	}
+
+	return xrs
+}
+
+func mustXcodeVersion(t *testing.T, s string) XcodeVersion {
+	t.Helper()
+
+	v, err := ParseXcodeVersion(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return v
+}
+
+func TestQuery(t *testing.T) {
+	xrs := testIndexReleases(t)
+	// shuffle the order of the input, which the Index must not depend on
+	in := []*XcodeRelease{xrs[3], xrs[7], xrs[0], xrs[5], xrs[1], xrs[6], xrs[4], xrs[2]}
+	ix := NewIndex(in)
+
+	v13, v133, v14 := mustXcodeVersion(t, "13.0"), mustXcodeVersion(t, "13.3"), mustXcodeVersion(t, "14.0")
+	dec1 := time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC)
+	dec17 := time.Date(2021, 12, 17, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		query *Query
+		want  []string
+	}{
+		{
+			name:  "All",
+			query: ix.Query(),
+			want:  []string{"14A5228q", "13E5086k", "13C100", "13C90", "13C90", "13A1030d", "12E507", "12D4e"},
+		},
+		{
+			name:  "Release",
+			query: ix.Query().Channel(ChannelRelease),
+			want:  []string{"13C100", "13C90", "13A1030d", "12E507", "12D4e"},
+		},
+		{
+			name:  "BetaOrRC",
+			query: ix.Query().Channel(ChannelBeta, ChannelRC),
+			want:  []string{"14A5228q", "13E5086k", "13C90"},
+		},
+		{
+			name:  "ChannelIntersection",
+			query: ix.Query().Channel(ChannelBeta, ChannelRC).Channel(ChannelRC, ChannelRelease),
+			want:  []string{"13C90"},
+		},
+		{
+			name:  "ChannelDisjoint",
+			query: ix.Query().Channel(ChannelBeta).Channel(ChannelRelease),
+			want:  nil,
+		},
+		{
+			name:  "VersionRange",
+			query: ix.Query().VersionRange(v13, v133),
+			want:  []string{"13E5086k", "13C100", "13C90", "13C90", "13A1030d"},
+		},
+		{
+			name:  "VersionRangeNoMax",
+			query: ix.Query().VersionRange(v14, XcodeVersion{}),
+			want:  nil,
+		},
+		{
+			name:  "VersionRangeFilter",
+			query: ix.Query().Where(VersionRange(v13, v133)),
+			want:  []string{"13E5086k", "13C100", "13C90", "13C90", "13A1030d"},
+		},
+		{
+			name:  "DateRange",
+			query: ix.Query().DateRange(dec1, dec17),
+			want:  []string{"13C100", "13C90", "13C90"},
+		},
+		{
+			name:  "DateRangeFrom",
+			query: ix.Query().DateRange(dec17, time.Time{}),
+			want:  []string{"14A5228q", "13E5086k", "13C100"},
+		},
+		{
+			name:  "DateRangeTo",
+			query: ix.Query().DateRange(time.Time{}, dec1),
+			want:  []string{"13A1030d", "12E507", "12D4e"},
+		},
+		{
+			name:  "HasSDK",
+			query: ix.Query().HasSDK(PlatformMacOS, "12.1"),
+			want:  []string{"13C100", "13C90", "13C90"},
+		},
+		{
+			name:  "HasSDKMajor",
+			query: ix.Query().HasSDK(PlatformIOS, "14"),
+			want:  []string{"12E507", "12D4e"},
+		},
+		{
+			name:  "HasSDKAny",
+			query: ix.Query().HasSDK(PlatformWatchOS, ""),
+			want:  nil,
+		},
+		{
+			name:  "ReleaseInRange",
+			query: ix.Query().Channel(ChannelRelease).VersionRange(v13, v133).HasSDK(PlatformMacOS, "12"),
+			want:  []string{"13C100", "13C90", "13A1030d"},
+		},
+		{
+			name:  "ReleaseInDateRange",
+			query: ix.Query().Channel(ChannelRelease).DateRange(dec1, time.Time{}),
+			want:  []string{"13C100", "13C90"},
+		},
+		{
+			name:  "Or",
+			query: ix.Query().Where(Or(InChannel(ChannelBeta), HasSDK(PlatformMacOS, "11.1"))),
+			want:  []string{"14A5228q", "13E5086k", "12D4e"},
+		},
+		{
+			name:  "AndNot",
+			query: ix.Query().Where(Not(InChannel(ChannelRelease)), Not(HasSDK(PlatformIOS, "16"))),
+			want:  []string{"13E5086k", "13C90"},
+		},
+	}
+	for _, tt := range tests {
+		if got := builds(tt.query.All()); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: All() = %v, want %v", tt.name, got, tt.want)
+		}
+		if got := tt.query.Count(); got != len(tt.want) {
+			t.Errorf("%s: Count() = %d, want %d", tt.name, got, len(tt.want))
+		}
+		first := tt.query.First()
+		if len(tt.want) == 0 && first != nil || len(tt.want) > 0 && (first == nil || first.Version.Build != tt.want[0]) {
+			t.Errorf("%s: First() = %v, want the first of %v", tt.name, first, tt.want)
+		}
+	}
+}
+
+func TestQueryImmutable(t *testing.T) {
+	ix := NewIndex(testIndexReleases(t))
+
+	q := ix.Query().Channel(ChannelRelease)
+	a := q.HasSDK(PlatformMacOS, "12")
+	b := q.HasSDK(PlatformMacOS, "11")
+	if got := builds(a.All()); !reflect.DeepEqual(got, []string{"13C100", "13C90", "13A1030d"}) {
+		t.Errorf("a.All() = %v", got)
+	}
+	if got := builds(b.All()); !reflect.DeepEqual(got, []string{"12E507", "12D4e"}) {
+		t.Errorf("b.All() = %v", got)
+	}
+	if got := q.Count(); got != 5 {
+		t.Errorf("q.Count() = %d, want 5", got)
+	}
+}
+
+func TestIndexHelpers(t *testing.T) {
+	ix := NewIndex(testIndexReleases(t))
+
+	latest := []struct {
+		ch   Channel
+		want string
+	}{
+		{ChannelRelease, "13C100"},
+		{ChannelBeta, "14A5228q"},
+		{ChannelRC, "13C90"},
+		{ChannelDP, ""},
+	}
+	for _, tt := range latest {
+		got := ""
+		if xr := ix.Latest(tt.ch); xr != nil {
+			got = xr.Version.Build
+		}
+		if got != tt.want {
+			t.Errorf("Latest(%v) = %q, want %q", tt.ch, got, tt.want)
+		}
+	}
+
+	if got := builds(ix.LatestPerMajor()); !reflect.DeepEqual(got, []string{"13C100", "12E507"}) {
+		t.Errorf("LatestPerMajor() = %v", got)
+	}
+
+	if xr := ix.FindByBuild("13C90"); xr == nil || xr.Version.Channel() != ChannelRelease {
+		t.Errorf("FindByBuild(13C90) = %v, want the final 13.2", xr)
+	}
+	if xr := ix.FindByBuild("13A1030d"); xr == nil || xr.Version.Number != "13.1" {
+		t.Errorf("FindByBuild(13A1030d) = %v", xr)
+	}
+	if xr := ix.FindByBuild("13C101"); xr != nil {
+		t.Errorf("FindByBuild(13C101) = %v, want nil", xr)
+	}
+}
+
+func TestMatchVersion(t *testing.T) {
+	tests := []struct {
+		pattern, v string
+		want       bool
+	}{
+		{"", "12.1", true},
+		{"12", "12.1", true},
+		{"12.1", "12.1", true},
+		{"12.1", "12.1.2", true},
+		{"12.1", "12.10", false},
+		{"12.1.0", "12.1", true},
+		{"12.1.1", "12.1", false},
+		{"12", "11.5", false},
+	}
+	for _, tt := range tests {
+		if got := matchVersion(tt.pattern, tt.v); got != tt.want {
+			t.Errorf("matchVersion(%q, %q) = %v, want %v", tt.pattern, tt.v, got, tt.want)
+		}
+	}
+}
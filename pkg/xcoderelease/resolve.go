@@ -0,0 +1,254 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// numNearest is the number of the nearest candidates reported by ResolveError.
+const numNearest = 5
+
+// ResolveError is returned when no release matches the specifier.
+type ResolveError struct {
+	// Spec is the specifier.
+	Spec string
+
+	// Nearest is the releases nearest to the specifier, newest first.
+	Nearest []*XcodeRelease
+}
+
+// Error implements error.
+func (e *ResolveError) Error() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "no Xcode release matches %q", e.Spec)
+	for i, xr := range e.Nearest {
+		if i == 0 {
+			sb.WriteString("; nearest: ")
+		} else {
+			sb.WriteString(", ")
+		}
+		if v, err := xr.Version.XcodeVersion(); err == nil {
+			sb.WriteString(v.String())
+		} else {
+			sb.WriteString(xr.Version.Build)
+		}
+	}
+
+	return sb.String()
+}
+
+// Resolve returns the release in xrs which best matches the specifier spec.
+//
+// See Index.Resolve for the specifier syntax.
+func Resolve(spec string, xrs []*XcodeRelease) (*XcodeRelease, error) {
+	return NewIndex(xrs).Resolve(spec)
+}
+
+// Resolve returns the release which best matches the .xcode-version style specifier spec.
+//
+// The specifier is one of:
+//
+//	latest              the newest release in any channel
+//	latest-stable       the newest final release
+//	latest-<channel>    the newest release in the channel, e.g. latest-beta
+//	13                  the newest final release of the major version
+//	13.2                the final release of the exact version
+//	13.2 beta 2         the exact pre-release, as accepted by ParseXcodeVersion
+//	13C100              the release of the build
+//	~13.1               the newest final release in [13.1, 13.2)
+//	^13.1               the newest final release in [13.1, 14)
+//	>=13.0 <14          the newest final release satisfying all of the comparisons
+//
+// If no release matches, it returns *ResolveError listing the nearest releases.
+func (ix *Index) Resolve(spec string) (*XcodeRelease, error) {
+	s := strings.TrimSpace(spec)
+	ls := strings.ToLower(s)
+
+	switch {
+	case s == "":
+		return nil, fmt.Errorf("resolve: empty specifier")
+
+	case ls == "latest":
+		if len(ix.releases) > 0 {
+			return ix.releases[0], nil
+		}
+		return nil, &ResolveError{Spec: spec}
+
+	case strings.HasPrefix(ls, "latest-"):
+		ch, err := ParseChannel(s[len("latest-"):])
+		if err != nil {
+			return nil, fmt.Errorf("resolve %q: %w", spec, err)
+		}
+		if xr := ix.Latest(ch); xr != nil {
+			return xr, nil
+		}
+		return nil, &ResolveError{Spec: spec, Nearest: ix.nearest(0)}
+
+	case strings.ContainsAny(s[:1], "<>=~^"):
+		cs, err := parseConstraints(s)
+		if err != nil {
+			return nil, fmt.Errorf("resolve %q: %w", spec, err)
+		}
+		for _, xr := range ix.byChannel[ChannelRelease] {
+			if cs.match(ix.versions[xr]) {
+				return xr, nil
+			}
+		}
+		return nil, &ResolveError{Spec: spec, Nearest: ix.nearestTo(cs[0].v)}
+	}
+
+	if _, err := ParseBuild(s); err == nil {
+		if xr := ix.FindByBuild(s); xr != nil {
+			return xr, nil
+		}
+		return nil, &ResolveError{Spec: spec, Nearest: ix.nearestBuild(s)}
+	}
+
+	v, err := ParseXcodeVersion(s)
+	if err != nil {
+		return nil, fmt.Errorf("resolve %q: %w", spec, err)
+	}
+
+	// a bare major version matches the newest final release of the major
+	if !strings.ContainsAny(s, ". ") {
+		for _, xr := range ix.byChannel[ChannelRelease] {
+			if ix.versions[xr].Major == v.Major {
+				return xr, nil
+			}
+		}
+		return nil, &ResolveError{Spec: spec, Nearest: ix.nearestTo(v)}
+	}
+
+	for _, xr := range ix.byChannel[v.Channel] {
+		xv := ix.versions[xr]
+		if xv.Compare(v) == 0 && (v.Build == "" || v.Build == xv.Build) {
+			return xr, nil
+		}
+	}
+
+	return nil, &ResolveError{Spec: spec, Nearest: ix.nearestTo(v)}
+}
+
+// nearest returns the releases around the position i of the ordered releases.
+func (ix *Index) nearest(i int) []*XcodeRelease {
+	lo := i - numNearest/2
+	if lo > len(ix.releases)-numNearest {
+		lo = len(ix.releases) - numNearest
+	}
+	if lo < 0 {
+		lo = 0
+	}
+	hi := lo + numNearest
+	if hi > len(ix.releases) {
+		hi = len(ix.releases)
+	}
+
+	return ix.releases[lo:hi]
+}
+
+// nearestTo returns the releases nearest to the version v.
+func (ix *Index) nearestTo(v XcodeVersion) []*XcodeRelease {
+	i := sort.Search(len(ix.releases), func(i int) bool {
+		return ix.versions[ix.releases[i]].Compare(v) <= 0
+	})
+
+	return ix.nearest(i)
+}
+
+// nearestBuild returns the releases nearest to the build.
+func (ix *Index) nearestBuild(build string) []*XcodeRelease {
+	b, _ := ParseBuild(build)
+
+	i := len(ix.releases)
+	for j, xr := range ix.releases {
+		if xb, err := xr.Version.ParseBuild(); err == nil && xb.Compare(b) <= 0 {
+			i = j
+			break
+		}
+	}
+
+	return ix.nearest(i)
+}
+
+// constraint is a single version comparison such as ">=13.0".
+type constraint struct {
+	op string
+	v  XcodeVersion
+}
+
+// constraints is the conjunction of the comparisons.
+type constraints []constraint
+
+// parseConstraints parses the space or comma separated comparisons of s.
+func parseConstraints(s string) (constraints, error) {
+	var cs constraints
+
+	fields := strings.Fields(strings.ReplaceAll(s, ",", " "))
+	for i := 0; i < len(fields); i++ {
+		f := fields[i]
+		op := f[:len(f)-len(strings.TrimLeft(f, "<>=~^"))]
+		num := f[len(op):]
+		if num == "" && i+1 < len(fields) { // operator separated from the version by a space
+			i++
+			num = fields[i]
+		}
+
+		major, minor, patch, err := parseNumber(num)
+		if err != nil {
+			return nil, err
+		}
+		v := XcodeVersion{Major: major, Minor: minor, Patch: patch}
+		parts := strings.Count(num, ".") + 1
+
+		switch op {
+		case "", "=", "==", "<", "<=", ">", ">=":
+			cs = append(cs, constraint{op: op, v: v})
+		case "~":
+			upper := XcodeVersion{Major: major + 1}
+			if parts >= 2 {
+				upper = XcodeVersion{Major: major, Minor: minor + 1}
+			}
+			cs = append(cs, constraint{op: ">=", v: v}, constraint{op: "<", v: upper})
+		case "^":
+			cs = append(cs, constraint{op: ">=", v: v}, constraint{op: "<", v: XcodeVersion{Major: major + 1}})
+		default:
+			return nil, fmt.Errorf("invalid operator %q", op)
+		}
+	}
+	if len(cs) == 0 {
+		return nil, fmt.Errorf("no version constraint")
+	}
+
+	return cs, nil
+}
+
+// match reports whether v satisfies all cs.
+func (cs constraints) match(v XcodeVersion) bool {
+	for _, c := range cs {
+		r := v.Compare(c.v)
+
+		var ok bool
+		switch c.op {
+		case "", "=", "==":
+			ok = r == 0
+		case "<":
+			ok = r < 0
+		case "<=":
+			ok = r <= 0
+		case ">":
+			ok = r > 0
+		case ">=":
+			ok = r >= 0
+		}
+		if !ok {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,162 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// testRelease returns the release of the version s in the ParseXcodeVersion format, e.g. "13.2 Beta 2 (13C5081f)".
+func testRelease(t *testing.T, s string) *XcodeRelease {
+	t.Helper()
+
+	v, err := ParseXcodeVersion(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	number := fmt.Sprintf("%d.%d", v.Major, v.Minor)
+	if v.Patch > 0 {
+		number += fmt.Sprintf(".%d", v.Patch)
+	}
+	r := &Release{}
+	switch v.Channel {
+	case ChannelRelease:
+		r.Release = true
+	case ChannelDP:
+		r.Dp = v.Pre
+	case ChannelBeta:
+		r.Beta = v.Pre
+	case ChannelGMSeed:
+		r.GmSeed = v.Pre
+	case ChannelGM:
+		r.Gm = true
+	case ChannelRC:
+		r.Rc = v.Pre
+	}
+
+	return &XcodeRelease{
+		Name:    "Xcode",
+		Version: Version{Build: v.Build, Number: number, Release: r},
+	}
+}
+
+func testReleases(t *testing.T, ss ...string) []*XcodeRelease {
+	t.Helper()
+
+	xrs := make([]*XcodeRelease, len(ss))
+	for i, s := range ss {
+		xrs[i] = testRelease(t, s)
+	}
+
+	return xrs
+}
+
+func TestResolve(t *testing.T) {
+	ix := NewIndex(testReleases(t,
+		"14.0 Beta 1 (14A5228q)",
+		"13.3 Beta 1 (13E5086k)",
+		"13.2.1 (13C100)",
+		"13.2 (13C90)",
+		"13.2 RC (13C5081f)",
+		"13.2 Beta 2 (13C5066c)",
+		"13.1 (13A1030d)",
+		"13.0 (13A233)",
+		"12.5.1 (12E507)",
+		"12.5 (12E262)",
+		"12.4 (12D4e)",
+	))
+
+	tests := []struct {
+		spec string
+		want string // build of the resolved release
+	}{
+		{spec: "latest", want: "14A5228q"},
+		{spec: " LATEST ", want: "14A5228q"},
+		{spec: "latest-stable", want: "13C100"},
+		{spec: "latest-release", want: "13C100"},
+		{spec: "latest-beta", want: "14A5228q"},
+		{spec: "latest-rc", want: "13C5081f"},
+		{spec: "13", want: "13C100"},
+		{spec: "12", want: "12E507"},
+		{spec: "13.2", want: "13C90"},
+		{spec: "13.2.1", want: "13C100"},
+		{spec: "13.2 beta 2", want: "13C5066c"},
+		{spec: "13.2 RC", want: "13C5081f"},
+		{spec: "13.2 (13C90)", want: "13C90"},
+		{spec: "13C5066c", want: "13C5066c"},
+		{spec: "12D4e", want: "12D4e"},
+		{spec: "~13.1", want: "13A1030d"},
+		{spec: "~13.2", want: "13C100"},
+		{spec: "~12", want: "12E507"},
+		{spec: "^12.4", want: "12E507"},
+		{spec: "^13.0", want: "13C100"},
+		{spec: ">=13.0 <13.2", want: "13A1030d"},
+		{spec: ">= 13.0, < 13.2", want: "13A1030d"},
+		{spec: "<13", want: "12E507"},
+		{spec: ">12.5 <=13.0", want: "13A233"},
+		{spec: "=12.5", want: "12E262"},
+	}
+	for _, tt := range tests {
+		xr, err := ix.Resolve(tt.spec)
+		if err != nil {
+			t.Errorf("Resolve(%q) error = %v", tt.spec, err)
+			continue
+		}
+		if xr.Version.Build != tt.want {
+			t.Errorf("Resolve(%q) = %s, want %s", tt.spec, xr.Version.Build, tt.want)
+		}
+	}
+}
+
+func TestResolveNoMatch(t *testing.T) {
+	ix := NewIndex(testReleases(t,
+		"13.2.1 (13C100)",
+		"13.2 (13C90)",
+		"13.1 (13A1030d)",
+	))
+
+	for _, spec := range []string{"11", "13.3", "13.2 beta 1", "13C101", "~14.0", ">=13.3", "latest-dp"} {
+		_, err := ix.Resolve(spec)
+
+		var rerr *ResolveError
+		if !errors.As(err, &rerr) {
+			t.Errorf("Resolve(%q) error = %v, want *ResolveError", spec, err)
+			continue
+		}
+		if rerr.Spec != spec {
+			t.Errorf("Resolve(%q) ResolveError.Spec = %q", spec, rerr.Spec)
+		}
+		if len(rerr.Nearest) == 0 {
+			t.Errorf("Resolve(%q) ResolveError.Nearest is empty", spec)
+		}
+	}
+}
+
+func TestResolveInvalid(t *testing.T) {
+	ix := NewIndex(testReleases(t, "13.2.1 (13C100)"))
+
+	for _, spec := range []string{"", "  ", "latest-alpha", "~13.x", "!13", ">=", "13.2 alpha 1"} {
+		_, err := ix.Resolve(spec)
+		if err == nil {
+			t.Errorf("Resolve(%q) error = nil, want an error", spec)
+			continue
+		}
+		var rerr *ResolveError
+		if errors.As(err, &rerr) {
+			t.Errorf("Resolve(%q) error = %v, want a syntax error", spec, err)
+		}
+	}
+}
+
+func TestResolveEmpty(t *testing.T) {
+	_, err := Resolve("latest", nil)
+
+	var rerr *ResolveError
+	if !errors.As(err, &rerr) {
+		t.Errorf("Resolve(latest) error = %v, want *ResolveError", err)
+	}
+}
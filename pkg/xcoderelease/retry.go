@@ -0,0 +1,136 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+)
+
+// RetryPolicy configures the retries of failed requests.
+//
+// Requests are retried on 429 and 5xx responses and on network errors,
+// waiting an exponential backoff with jitter between attempts.
+// The Retry-After header of the response takes precedence over the computed backoff, up to MaxBackoff.
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of retries after the first attempt.
+	MaxRetries int
+
+	// MinBackoff is the wait before the first retry. It doubles on each retry.
+	MinBackoff time.Duration
+
+	// MaxBackoff caps the computed backoff, including the jitter, and the Retry-After of the responses.
+	MaxBackoff time.Duration
+
+	// Jitter is the fraction of the backoff randomly added or subtracted, in range [0, 1].
+	Jitter float64
+}
+
+// DefaultRetryPolicy is a RetryPolicy suitable for most callers.
+var DefaultRetryPolicy = &RetryPolicy{
+	MaxRetries: 3,
+	MinBackoff: 500 * time.Millisecond,
+	MaxBackoff: 30 * time.Second,
+	Jitter:     0.2,
+}
+
+var (
+	randMu sync.Mutex
+	rnd    = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// randFloat64 returns a random number in [0.0, 1.0).
+func randFloat64() float64 {
+	randMu.Lock()
+	defer randMu.Unlock()
+
+	return rnd.Float64()
+}
+
+// jitter returns d randomly spread by the fraction j.
+func jitter(d time.Duration, j float64) time.Duration {
+	if j <= 0 || d <= 0 {
+		return d
+	}
+	if j > 1 {
+		j = 1
+	}
+
+	return d + time.Duration((randFloat64()*2-1)*j*float64(d))
+}
+
+// backoff returns the wait before the retry numbered attempt, starting at 0.
+//
+// The retryAfter requested by the server is capped by MaxBackoff, so that a server cannot stall the client.
+func (p *RetryPolicy) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		if p.MaxBackoff > 0 && retryAfter > p.MaxBackoff {
+			return p.MaxBackoff
+		}
+		return retryAfter
+	}
+
+	d := p.MinBackoff
+	for i := 0; i < attempt && (p.MaxBackoff <= 0 || d < p.MaxBackoff); i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	d = jitter(d, p.Jitter)
+	if p.MaxBackoff > 0 && d > p.MaxBackoff { // the jitter may exceed the cap
+		d = p.MaxBackoff
+	}
+
+	return d
+}
+
+// retryable reports whether the request failed with err may succeed if retried.
+func retryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Temporary()
+	}
+
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return true // connection closed in the middle of the body
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// retry calls fn until it succeeds, returns a non-retryable error or the policy gives up.
+// A nil policy calls fn once.
+func (p *RetryPolicy) retry(ctx context.Context, fn func() error) error {
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = fn(); err == nil || p == nil || attempt >= p.MaxRetries || !retryable(err) {
+			return err
+		}
+
+		var retryAfter time.Duration
+		var httpErr *HTTPError
+		if errors.As(err, &httpErr) {
+			retryAfter = httpErr.RetryAfter
+		}
+
+		t := time.NewTimer(p.backoff(attempt, retryAfter))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHTTPError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "2")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(strings.Repeat("x", 2*maxErrorBody)))
+	}))
+	defer srv.Close()
+
+	c := &Client{URL: mustParseURL(t, srv.URL)}
+	_, err := c.DownloadJSON(context.Background())
+
+	var herr *HTTPError
+	if !errors.As(err, &herr) {
+		t.Fatalf("DownloadJSON() error = %v, want *HTTPError", err)
+	}
+	if herr.StatusCode != http.StatusServiceUnavailable || herr.Status != "503 Service Unavailable" || herr.URL != srv.URL {
+		t.Errorf("HTTPError = %d %q %q", herr.StatusCode, herr.Status, herr.URL)
+	}
+	if len(herr.Body) != maxErrorBody {
+		t.Errorf("len(Body) = %d, want %d", len(herr.Body), maxErrorBody)
+	}
+	if herr.RetryAfter != 2*time.Second {
+		t.Errorf("RetryAfter = %v, want 2s", herr.RetryAfter)
+	}
+	if !herr.Temporary() {
+		t.Error("Temporary() = false, want true")
+	}
+	if got, want := herr.Error(), "GET "+srv.URL+": 503 Service Unavailable: \"xxx"; !strings.HasPrefix(got, want) {
+		t.Errorf("Error() = %q, want the prefix %q", got, want)
+	}
+}
+
+func TestHTTPErrorTemporary(t *testing.T) {
+	for code, want := range map[int]bool{
+		http.StatusTooManyRequests:     true,
+		http.StatusInternalServerError: true,
+		http.StatusBadGateway:          true,
+		http.StatusNotFound:            false,
+		http.StatusForbidden:           false,
+		http.StatusNotModified:         false,
+	} {
+		if got := (&HTTPError{StatusCode: code}).Temporary(); got != want {
+			t.Errorf("HTTPError{%d}.Temporary() = %v, want %v", code, got, want)
+		}
+	}
+
+	e := &HTTPError{URL: "https://xcodereleases.com/data.json", Status: "404 Not Found"}
+	if got, want := e.Error(), "GET https://xcodereleases.com/data.json: 404 Not Found"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2021, 12, 17, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{in: "", want: 0},
+		{in: "0", want: 0},
+		{in: "120", want: 2 * time.Minute},
+		{in: "-1", want: 0},
+		{in: "Fri, 17 Dec 2021 12:00:30 GMT", want: 30 * time.Second},
+		{in: "Fri, 17 Dec 2021 11:59:00 GMT", want: 0},
+		{in: "soon", want: 0},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.in, now); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	p := &RetryPolicy{MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Jitter: 0.5}
+
+	for attempt := 0; attempt < 8; attempt++ {
+		base := p.MinBackoff << uint(attempt)
+		if base > p.MaxBackoff {
+			base = p.MaxBackoff
+		}
+		lo, hi := base/2, base+base/2
+		if hi > p.MaxBackoff {
+			hi = p.MaxBackoff
+		}
+		for i := 0; i < 100; i++ {
+			if d := p.backoff(attempt, 0); d < lo || d > hi {
+				t.Fatalf("backoff(%d) = %v, want in [%v, %v]", attempt, d, lo, hi)
+			}
+		}
+	}
+
+	if d := p.backoff(0, 200*time.Millisecond); d != 200*time.Millisecond {
+		t.Errorf("backoff(Retry-After 200ms) = %v, want 200ms", d)
+	}
+	if d := p.backoff(0, time.Hour); d != p.MaxBackoff {
+		t.Errorf("backoff(Retry-After 1h) = %v, want the cap %v", d, p.MaxBackoff)
+	}
+}
+
+func TestRetryStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &RetryPolicy{MaxRetries: 5, MinBackoff: time.Hour}
+
+	var n int
+	err := p.retry(ctx, func() error {
+		n++
+		cancel()
+		return &HTTPError{StatusCode: http.StatusServiceUnavailable}
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("retry() error = %v, want %v", err, context.Canceled)
+	}
+	if n != 1 {
+		t.Errorf("retry() called fn %d times, want 1", n)
+	}
+}
+
+func TestDownloadJSONRetry(t *testing.T) {
+	tests := []struct {
+		name string
+		// failures is the number of the failed responses before a 200.
+		failures   int
+		status     int
+		wantStatus int // status of *HTTPError, or 0 for success
+		wantN      int // number of the requests
+	}{
+		{name: "Recovered", failures: 2, status: http.StatusServiceUnavailable, wantN: 3},
+		{name: "TooManyRequests", failures: 1, status: http.StatusTooManyRequests, wantN: 2},
+		{name: "GiveUp", failures: 10, status: http.StatusBadGateway, wantStatus: http.StatusBadGateway, wantN: 4},
+		{name: "NotFound", failures: 10, status: http.StatusNotFound, wantStatus: http.StatusNotFound, wantN: 1},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var n int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if int(atomic.AddInt32(&n, 1)) <= tt.failures {
+					w.Header().Set("Retry-After", "0")
+					w.WriteHeader(tt.status)
+					return
+				}
+				w.Write([]byte(`[]`))
+			}))
+			defer srv.Close()
+
+			c := &Client{
+				URL:   mustParseURL(t, srv.URL),
+				Retry: &RetryPolicy{MaxRetries: 3, MinBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond},
+			}
+			data, err := c.DownloadJSON(context.Background())
+			if tt.wantStatus == 0 {
+				if err != nil || string(data) != `[]` {
+					t.Errorf("DownloadJSON() = %q, %v", data, err)
+				}
+			} else {
+				var herr *HTTPError
+				if !errors.As(err, &herr) || herr.StatusCode != tt.wantStatus {
+					t.Errorf("DownloadJSON() error = %v, want HTTPError %d", err, tt.wantStatus)
+				}
+			}
+			if got := int(atomic.LoadInt32(&n)); got != tt.wantN {
+				t.Errorf("DownloadJSON() sent %d requests, want %d", got, tt.wantN)
+			}
+		})
+	}
+}
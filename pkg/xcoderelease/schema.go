@@ -0,0 +1,300 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	json "github.com/goccy/go-json"
+)
+
+// DecodeMode selects how Client.Unmarshal treats the data which does not follow the known schema.
+type DecodeMode int
+
+const (
+	// DecodeDefault ignores the unknown fields and fails on the first type mismatch.
+	DecodeDefault DecodeMode = iota
+
+	// DecodeStrict reports all schema violations as *SchemaError. See UnmarshalStrict.
+	DecodeStrict
+
+	// DecodeLenient keeps the unknown fields in the Extra fields. See UnmarshalLenient.
+	DecodeLenient
+)
+
+// Issue is a schema violation located in the JSON document.
+type Issue struct {
+	// Path is the location of the violation, e.g. "$[3].version.release.beta".
+	Path string
+
+	// Message describes the violation.
+	Message string
+}
+
+// String returns the located message of i.
+func (i Issue) String() string {
+	return i.Path + ": " + i.Message
+}
+
+// SchemaError is returned by UnmarshalStrict when the data does not follow the known schema.
+type SchemaError struct {
+	Issues []Issue
+}
+
+// Error implements error.
+func (e *SchemaError) Error() string {
+	const max = 5
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "schema mismatch: %d issue(s)", len(e.Issues))
+	for i, is := range e.Issues {
+		if i == max {
+			fmt.Fprintf(&sb, "; and %d more", len(e.Issues)-max)
+			break
+		}
+		sb.WriteString("; ")
+		sb.WriteString(is.String())
+	}
+
+	return sb.String()
+}
+
+// requiredFields lists the JSON fields which every entry of data.json has.
+var requiredFields = map[reflect.Type][]string{
+	reflect.TypeOf(XcodeRelease{}):    {"name", "version", "date"},
+	reflect.TypeOf(Version{}):         {"number"},
+	reflect.TypeOf(SDK{}):             {"number"},
+	reflect.TypeOf(ClangCompiler{}):   {"number"},
+	reflect.TypeOf(GCCCompiler{}):     {"number"},
+	reflect.TypeOf(LLVMCompiler{}):    {"number"},
+	reflect.TypeOf(LLVMGCCCompiler{}): {"number"},
+	reflect.TypeOf(SwiftCompiler{}):   {"number"},
+	reflect.TypeOf(Date{}):            {"day", "month", "year"},
+	reflect.TypeOf(URL{}):             {"url"},
+}
+
+var (
+	sdksType   = reflect.TypeOf(SDKs{})
+	extraType  = reflect.TypeOf((*Extra)(nil))
+	rawMsgType = reflect.TypeOf(json.RawMessage(nil))
+)
+
+// UnmarshalStrict parses data like Unmarshal, and reports the unknown fields, the missing required fields
+// and the type mismatches as *SchemaError listing all of them.
+func UnmarshalStrict(data []byte) ([]*XcodeRelease, error) {
+	w := &schemaWalker{strict: true}
+	w.walk(data, reflect.TypeOf([]*XcodeRelease(nil)), reflect.Value{}, "$")
+	if len(w.issues) > 0 {
+		return nil, &SchemaError{Issues: w.issues}
+	}
+
+	return unmarshal(data)
+}
+
+// UnmarshalLenient parses data like Unmarshal, and keeps the unknown fields as json.RawMessage
+// in the Extra field of the struct which they belong to.
+//
+// The SDKs of the unknown platforms are kept in SDKs.Other in any mode.
+func UnmarshalLenient(data []byte) ([]*XcodeRelease, error) {
+	xrs, err := unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &schemaWalker{}
+	w.walk(data, reflect.TypeOf(xrs), reflect.ValueOf(xrs), "$")
+
+	return xrs, nil
+}
+
+// schemaWalker walks a JSON document along the Go type which it decodes into.
+//
+// In strict mode it collects the schema violations, otherwise it stores the unknown fields into
+// the Extra fields of the decoded value.
+type schemaWalker struct {
+	strict bool
+	issues []Issue
+}
+
+func (w *schemaWalker) report(path, format string, args ...interface{}) {
+	if w.strict {
+		w.issues = append(w.issues, Issue{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+}
+
+// jsonKind returns the kind of the JSON value raw, e.g. "object" or "number".
+func jsonKind(raw []byte) string {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return "empty"
+	}
+
+	switch raw[0] {
+	case '{':
+		return "object"
+	case '[':
+		return "array"
+	case '"':
+		return "string"
+	case 't', 'f':
+		return "boolean"
+	case 'n':
+		return "null"
+	default:
+		return "number"
+	}
+}
+
+// walk walks raw decoded into the type t. The value v is the decoded value, or invalid in strict mode.
+func (w *schemaWalker) walk(raw []byte, t reflect.Type, v reflect.Value, path string) {
+	kind := jsonKind(raw)
+	if kind == "null" && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Map) {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		if v.IsValid() {
+			v = v.Elem()
+		}
+		w.walk(raw, t.Elem(), v, path)
+
+	case reflect.Slice:
+		if t == rawMsgType {
+			return
+		}
+		if kind != "array" {
+			w.report(path, "expected array, got %s", kind)
+			return
+		}
+		var elems []json.RawMessage
+		if err := json.Unmarshal(raw, &elems); err != nil {
+			w.report(path, "%v", err)
+			return
+		}
+		for i, elem := range elems {
+			var ev reflect.Value
+			if v.IsValid() && i < v.Len() {
+				ev = v.Index(i)
+			}
+			w.walk(elem, t.Elem(), ev, path+"["+strconv.Itoa(i)+"]")
+		}
+
+	case reflect.Struct:
+		if kind != "object" {
+			w.report(path, "expected object, got %s", kind)
+			return
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			w.report(path, "%v", err)
+			return
+		}
+		if t == sdksType {
+			w.walkSDKs(fields, v, path)
+			return
+		}
+		w.walkStruct(fields, t, v, path)
+
+	case reflect.String:
+		if kind != "string" {
+			w.report(path, "expected string, got %s", kind)
+		}
+
+	case reflect.Bool:
+		if kind != "boolean" {
+			w.report(path, "expected boolean, got %s", kind)
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if kind != "number" {
+			w.report(path, "expected number, got %s", kind)
+		} else if _, err := strconv.ParseInt(string(bytes.TrimSpace(raw)), 10, 64); err != nil {
+			w.report(path, "expected integer, got %s", bytes.TrimSpace(raw))
+		}
+	}
+}
+
+// walkStruct walks the object fields decoded into the struct type t.
+func (w *schemaWalker) walkStruct(fields map[string]json.RawMessage, t reflect.Type, v reflect.Value, path string) {
+	known := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		known[name] = true
+
+		raw, ok := fields[name]
+		if !ok {
+			continue
+		}
+		var fv reflect.Value
+		if v.IsValid() {
+			fv = v.Field(i)
+		}
+		w.walk(raw, f.Type, fv, path+"."+name)
+	}
+
+	for _, name := range requiredFields[t] {
+		if _, ok := fields[name]; !ok {
+			w.report(path, "missing required field %q", name)
+		}
+	}
+
+	var extra *Extra
+	for _, name := range sortedKeys(fields) {
+		if known[name] {
+			continue
+		}
+		w.report(path+"."+name, "unknown field")
+		if extra == nil {
+			extra = &Extra{Fields: make(map[string]json.RawMessage)}
+		}
+		extra.Fields[name] = fields[name]
+	}
+
+	if extra != nil && v.IsValid() {
+		if ev := v.FieldByName("Extra"); ev.IsValid() && ev.Type() == extraType && ev.CanSet() {
+			ev.Set(reflect.ValueOf(extra))
+		}
+	}
+}
+
+// walkSDKs walks the sdks object, whose keys are the platform names.
+func (w *schemaWalker) walkSDKs(fields map[string]json.RawMessage, v reflect.Value, path string) {
+	var sdks *SDKs
+	if v.IsValid() {
+		sdks = v.Addr().Interface().(*SDKs)
+	}
+
+	for _, name := range sortedKeys(fields) {
+		p := Platform(name)
+		if (&SDKs{}).field(p) == nil {
+			w.report(path+"."+name, "unknown platform")
+		}
+
+		var sv reflect.Value
+		if sdks != nil {
+			sv = reflect.ValueOf(sdks.For(p))
+		}
+		w.walk(fields[name], reflect.TypeOf([]SDK(nil)), sv, path+"."+name)
+	}
+}
+
+func sortedKeys(m map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,146 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testSchemaJSON = `[
+  {
+    "name": "Xcode",
+    "version": {"build": "13C100", "number": "13.2.1", "release": {"release": true}},
+    "date": {"day": 17, "month": 12, "year": 2021},
+    "requires": "11.3",
+    "sdks": {"iOS": [{"build": "19C51", "number": "15.2", "release": true}]},
+    "compilers": {"clang": [{"build": "1300.0.29.30", "number": "13.0.0", "release": true}]},
+    "checksums": {"sha1": "b8ffd8a5ed3cdfa0b8e4a26d7f3a1c6d9c7d3f1e"},
+    "links": {"download": {"url": "https://download.developer.apple.com/Xcode_13.2.1.xip"}}
+  }
+]`
+
+func TestUnmarshalStrict(t *testing.T) {
+	xrs, err := UnmarshalStrict([]byte(testSchemaJSON))
+	if err != nil {
+		t.Fatalf("UnmarshalStrict() error = %v", err)
+	}
+	if len(xrs) != 1 || xrs[0].Version.Build != "13C100" {
+		t.Fatalf("UnmarshalStrict() = %v", xrs)
+	}
+}
+
+func TestUnmarshalStrictIssues(t *testing.T) {
+	const data = `[
+  {
+    "name": "Xcode",
+    "version": {"build": "13C100", "number": 13.2, "release": {"release": true, "alpha": 1}},
+    "date": {"day": 17, "month": 12},
+    "sdks": {"visionOS": [{"number": "1.0"}], "iOS": {"number": "15.2"}},
+    "compilers": {"swift": [{"number": "5.5.2", "release": "yes"}]},
+    "signature": "x"
+  },
+  null,
+  {"name": "Xcode", "version": {"number": "13.2", "release": {"beta": 1.5}}, "date": {"day": 1, "month": 1, "year": 2022}}
+]`
+
+	_, err := UnmarshalStrict([]byte(data))
+
+	var serr *SchemaError
+	if !errors.As(err, &serr) {
+		t.Fatalf("UnmarshalStrict() error = %v, want *SchemaError", err)
+	}
+
+	want := []Issue{
+		{Path: "$[0].sdks.iOS", Message: "expected array, got object"},
+		{Path: "$[0].sdks.visionOS", Message: "unknown platform"},
+		{Path: "$[0].version.number", Message: "expected string, got number"},
+		{Path: "$[0].version.release.alpha", Message: "unknown field"},
+		{Path: "$[0].compilers.swift[0].release", Message: "expected boolean, got string"},
+		{Path: "$[0].date", Message: `missing required field "year"`},
+		{Path: "$[0].signature", Message: "unknown field"},
+		{Path: "$[2].version.release.beta", Message: "expected integer, got 1.5"},
+	}
+	if !reflect.DeepEqual(serr.Issues, want) {
+		t.Errorf("UnmarshalStrict() issues =\n%v\nwant\n%v", serr.Issues, want)
+	}
+}
+
+func TestUnmarshalStrictNotArray(t *testing.T) {
+	_, err := UnmarshalStrict([]byte(`{"name": "Xcode"}`))
+
+	var serr *SchemaError
+	if !errors.As(err, &serr) || len(serr.Issues) != 1 || serr.Issues[0].Path != "$" {
+		t.Errorf("UnmarshalStrict() error = %v, want a SchemaError at $", err)
+	}
+}
+
+func TestUnmarshalLenient(t *testing.T) {
+	const data = `[
+  {
+    "name": "Xcode",
+    "version": {"build": "13C100", "number": "13.2.1", "release": {"release": true}, "arch": ["arm64"]},
+    "date": {"day": 17, "month": 12, "year": 2021},
+    "sdks": {"visionOS": [{"number": "1.0", "variant": "sim"}]},
+    "signature": "x"
+  },
+  {"name": "Xcode", "version": {"build": "13C90", "number": "13.2"}, "date": {"day": 13, "month": 12, "year": 2021}}
+]`
+
+	c := &Client{Mode: DecodeLenient}
+	xrs, err := c.Unmarshal([]byte(data))
+	if err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if len(xrs) != 2 || xrs[0].Version.Build != "13C100" || xrs[1].Version.Build != "13C90" {
+		t.Fatalf("Unmarshal() = %v", xrs)
+	}
+
+	extra := func(e *Extra, name string) string {
+		if e == nil {
+			return ""
+		}
+		return string(e.Fields[name])
+	}
+	if got := extra(xrs[0].Extra, "signature"); got != `"x"` {
+		t.Errorf("Extra[signature] = %s", got)
+	}
+	if got := extra(xrs[0].Version.Extra, "arch"); got != `["arm64"]` {
+		t.Errorf("Version.Extra[arch] = %s", got)
+	}
+	if xrs[0].Version.Release.Extra != nil || xrs[0].Date.Extra != nil {
+		t.Errorf("Extra of the known fields = %v, %v, want nil", xrs[0].Version.Release.Extra, xrs[0].Date.Extra)
+	}
+	sdks := xrs[0].SDKs.For("visionOS")
+	if len(sdks) != 1 || sdks[0].Number != "1.0" {
+		t.Fatalf("SDKs.For(visionOS) = %v", sdks)
+	}
+	if got := extra(sdks[0].Extra, "variant"); got != `"sim"` {
+		t.Errorf("SDKs.For(visionOS)[0].Extra[variant] = %s", got)
+	}
+	if xrs[1].Extra != nil || xrs[1].Version.Extra != nil {
+		t.Errorf("Extra of the second release = %v, %v, want nil", xrs[1].Extra, xrs[1].Version.Extra)
+	}
+
+	// the structs stay comparable with the Extra fields
+	if xrs[1].Date != (Date{Day: 13, Month: 12, Year: 2021}) {
+		t.Errorf("Date = %v", xrs[1].Date)
+	}
+
+	out, err := Marshal(xrs[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(out), "signature") || strings.Contains(string(out), "arm64") {
+		t.Errorf("Marshal() = %s, want the Extra fields omitted", out)
+	}
+}
+
+func TestUnmarshalLenientTypeMismatch(t *testing.T) {
+	if _, err := UnmarshalLenient([]byte(`[{"name": 1}]`)); err == nil {
+		t.Error("UnmarshalLenient() error = nil, want the type mismatch")
+	}
+}
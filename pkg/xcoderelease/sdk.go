@@ -0,0 +1,166 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"bytes"
+	"sort"
+
+	json "github.com/goccy/go-json"
+)
+
+// Platform represents an Apple OS platform, named as the key of data.json sdks object.
+type Platform string
+
+// List of known platforms.
+const (
+	PlatformMacOS   Platform = "macOS"
+	PlatformIOS     Platform = "iOS"
+	PlatformTvOS    Platform = "tvOS"
+	PlatformWatchOS Platform = "watchOS"
+)
+
+// knownPlatforms is the known platforms in the order returned by SDKs.Platforms.
+var knownPlatforms = []Platform{PlatformMacOS, PlatformIOS, PlatformTvOS, PlatformWatchOS}
+
+// ParsePlatform returns the platform named s case-insensitively, e.g. "macos" for PlatformMacOS.
+// The unknown names are returned as is.
+func ParsePlatform(s string) Platform {
+	for _, p := range knownPlatforms {
+		if equalFoldASCII(string(p), s) {
+			return p
+		}
+	}
+
+	return Platform(s)
+}
+
+func equalFoldASCII(a, b string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := 0; i < len(a); i++ {
+		x, y := a[i], b[i]
+		if isUpper(x) {
+			x += 'a' - 'A'
+		}
+		if isUpper(y) {
+			y += 'a' - 'A'
+		}
+		if x != y {
+			return false
+		}
+	}
+
+	return true
+}
+
+// field returns the pointer to the field of the known platform p, or nil if p is unknown.
+func (s *SDKs) field(p Platform) *[]SDK {
+	switch p {
+	case PlatformMacOS:
+		return &s.MacOS
+	case PlatformIOS:
+		return &s.IOS
+	case PlatformTvOS:
+		return &s.TvOS
+	case PlatformWatchOS:
+		return &s.WatchOS
+	default:
+		return nil
+	}
+}
+
+// For returns the SDKs of platform p.
+func (s *SDKs) For(p Platform) []SDK {
+	if s == nil {
+		return nil
+	}
+	if f := s.field(p); f != nil {
+		return *f
+	}
+
+	return s.Other[p]
+}
+
+// Set sets the SDKs of platform p.
+func (s *SDKs) Set(p Platform, sdks []SDK) {
+	if f := s.field(p); f != nil {
+		*f = sdks
+		return
+	}
+
+	if s.Other == nil {
+		s.Other = make(map[Platform][]SDK)
+	}
+	s.Other[p] = sdks
+}
+
+// Platforms returns the platforms listed in s.
+// The known platforms come first, followed by the unknown ones in lexical order.
+func (s *SDKs) Platforms() []Platform {
+	if s == nil {
+		return nil
+	}
+
+	var ps []Platform
+	for _, p := range knownPlatforms {
+		if *s.field(p) != nil {
+			ps = append(ps, p)
+		}
+	}
+
+	others := make([]Platform, 0, len(s.Other))
+	for p := range s.Other {
+		others = append(others, p)
+	}
+	sort.Slice(others, func(i, j int) bool { return others[i] < others[j] })
+
+	return append(ps, others...)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *SDKs) UnmarshalJSON(data []byte) error {
+	var m map[Platform][]SDK
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+
+	*s = SDKs{}
+	for p, sdks := range m {
+		s.Set(p, sdks)
+	}
+
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+//
+// The platforms are encoded in lexical order, as data.json does.
+func (s SDKs) MarshalJSON() ([]byte, error) {
+	ps := s.Platforms()
+	sort.Slice(ps, func(i, j int) bool { return ps[i] < ps[j] })
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, p := range ps {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(string(p))
+		if err != nil {
+			return nil, err
+		}
+		val, err := json.Marshal(s.For(p))
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
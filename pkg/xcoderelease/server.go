@@ -0,0 +1,251 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server is the http.Handler serving the releases over a small read-only REST API:
+//
+//	GET /data.json          the data.json as loaded
+//	GET /releases           the releases, newest first, narrowed by the query parameters
+//	GET /releases/{build}   the release of the build
+//	GET /latest             the latest release in the channel query parameter, release by default
+//
+// The /releases query parameters are channel (comma separated), platform and sdk, since and until (YYYY-MM-DD),
+// host (the macOS version the releases run on), version (a specifier accepted by Resolve) and limit.
+//
+// All the responses are JSON and carry the ETag of the loaded data.json, so the clients can revalidate them
+// with If-None-Match. Use http.StripPrefix to mount the Server under a path.
+//
+// The zero Server responds 503 Service Unavailable until Update loads the data.
+type Server struct {
+	mu      sync.RWMutex
+	ix      *Index
+	data    []byte
+	etag    string
+	modTime time.Time
+}
+
+// NewServer returns the new Server of the data.json data.
+func NewServer(data []byte) (*Server, error) {
+	s := new(Server)
+	if err := s.Update(data); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Update replaces the data.json served by s. The data is left unchanged on error.
+func (s *Server) Update(data []byte) error {
+	xrs, err := Unmarshal(data)
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(data)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if etag != s.etag {
+		s.ix = NewIndex(xrs)
+		s.data = data
+		s.etag = etag
+		s.modTime = time.Now()
+	}
+
+	return nil
+}
+
+// snapshot returns the currently served data.
+func (s *Server) snapshot() (ix *Index, data []byte, etag string, modTime time.Time) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.ix, s.data, s.etag, s.modTime
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		serveError(w, http.StatusMethodNotAllowed, "method %s not allowed", r.Method)
+		return
+	}
+
+	ix, data, etag, modTime := s.snapshot()
+	if ix == nil {
+		serveError(w, http.StatusServiceUnavailable, "no release data loaded")
+		return
+	}
+
+	var (
+		v   interface{}
+		err error
+	)
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	switch {
+	case path == "/data.json":
+		serveJSON(w, r, data, etag, modTime)
+		return
+	case path == "/releases":
+		v, err = queryReleases(ix, r)
+	case strings.HasPrefix(path, "/releases/"):
+		build := strings.TrimPrefix(path, "/releases/")
+		xr := ix.FindByBuild(build)
+		if xr == nil {
+			serveError(w, http.StatusNotFound, "no release of build %q", build)
+			return
+		}
+		v = xr
+	case path == "/latest":
+		v, err = latestRelease(ix, r)
+	default:
+		serveError(w, http.StatusNotFound, "%s not found", r.URL.Path)
+		return
+	}
+	if err != nil {
+		status := http.StatusBadRequest
+		var rerr *ResolveError
+		if errors.As(err, &rerr) {
+			status = http.StatusNotFound
+		}
+		serveError(w, status, "%v", err)
+		return
+	}
+
+	body, err := marshalJSON(v)
+	if err != nil {
+		serveError(w, http.StatusInternalServerError, "%v", err)
+		return
+	}
+	serveJSON(w, r, append(body, '\n'), etag, modTime)
+}
+
+// queryReleases returns the releases narrowed by the query parameters of r.
+func queryReleases(ix *Index, r *http.Request) ([]*XcodeRelease, error) {
+	params := r.URL.Query()
+	q := ix.Query()
+
+	if s := params.Get("channel"); s != "" {
+		var chs []Channel
+		for _, name := range strings.Split(s, ",") {
+			ch, err := ParseChannel(name)
+			if err != nil {
+				return nil, err
+			}
+			chs = append(chs, ch)
+		}
+		q = q.Channel(chs...)
+	}
+
+	if p := params.Get("platform"); p != "" {
+		q = q.HasSDK(ParsePlatform(p), params.Get("sdk"))
+	} else if params.Get("sdk") != "" {
+		return nil, fmt.Errorf("sdk requires platform")
+	}
+
+	var since, until time.Time
+	for _, d := range []struct {
+		name string
+		t    *time.Time
+	}{{"since", &since}, {"until", &until}} {
+		if s := params.Get(d.name); s != "" {
+			t, err := time.Parse("2006-01-02", s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s %q, want YYYY-MM-DD", d.name, s)
+			}
+			*d.t = t
+		}
+	}
+	q = q.DateRange(since, until)
+
+	if s := params.Get("host"); s != "" {
+		host, err := ParseOSVersion(s)
+		if err != nil {
+			return nil, err
+		}
+		q = q.RunsOn(host)
+	}
+
+	if spec := params.Get("version"); spec != "" {
+		xr, err := ix.Resolve(spec)
+		if err != nil {
+			return nil, err
+		}
+		q = q.Where(func(x *XcodeRelease) bool { return x == xr })
+	}
+
+	xrs := q.All()
+	if s := params.Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid limit %q", s)
+		}
+		if n < len(xrs) {
+			xrs = xrs[:n]
+		}
+	}
+	if xrs == nil {
+		xrs = []*XcodeRelease{}
+	}
+
+	return xrs, nil
+}
+
+// latestRelease returns the latest release in the channel query parameter of r.
+func latestRelease(ix *Index, r *http.Request) (*XcodeRelease, error) {
+	ch := ChannelRelease
+	if s := r.URL.Query().Get("channel"); s != "" {
+		var err error
+		if ch, err = ParseChannel(s); err != nil {
+			return nil, err
+		}
+	}
+
+	xr := ix.Latest(ch)
+	if xr == nil {
+		return nil, &ResolveError{Spec: "latest-" + ch.String()}
+	}
+
+	return xr, nil
+}
+
+func serveJSON(w http.ResponseWriter, r *http.Request, body []byte, etag string, modTime time.Time) {
+	h := w.Header()
+	h.Set("Content-Type", "application/json; charset=utf-8")
+	h.Set("ETag", etag)
+	h.Set("Cache-Control", "no-cache")
+
+	http.ServeContent(w, r, "", modTime, bytes.NewReader(body))
+}
+
+type serverError struct {
+	Error string `json:"error"`
+}
+
+func serveError(w http.ResponseWriter, status int, format string, args ...interface{}) {
+	body, err := marshalJSON(serverError{Error: fmt.Sprintf(format, args...)})
+	if err != nil {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	w.Write(append(body, '\n'))
+}
@@ -0,0 +1,57 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestServerZeroValue(t *testing.T) {
+	var s Server
+
+	for _, path := range []string{"/data.json", "/releases", "/releases/13C100", "/latest"} {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("GET %s = %d, want %d", path, rec.Code, http.StatusServiceUnavailable)
+		}
+	}
+}
+
+func TestServerStatus(t *testing.T) {
+	data, err := Marshal(testReleases(t, "13.2.1 (13C100)", "13.3 Beta 1 (13E5086k)"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewServer(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{path: "/data.json", want: http.StatusOK},
+		{path: "/releases?channel=beta", want: http.StatusOK},
+		{path: "/releases/13C100", want: http.StatusOK},
+		{path: "/releases/13C101", want: http.StatusNotFound},
+		{path: "/releases?version=13.2.1", want: http.StatusOK},
+		{path: "/releases?version=12", want: http.StatusNotFound},
+		{path: "/releases?version=~x", want: http.StatusBadRequest},
+		{path: "/releases?channel=alpha", want: http.StatusBadRequest},
+		{path: "/latest", want: http.StatusOK},
+		{path: "/latest?channel=rc", want: http.StatusNotFound},
+		{path: "/unknown", want: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != tt.want {
+			t.Errorf("GET %s = %d, want %d: %s", tt.path, rec.Code, tt.want, rec.Body)
+		}
+	}
+}
@@ -0,0 +1,391 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Stats is the timeline statistics of the releases.
+type Stats struct {
+	// Cycles is the beta cycles grouped by the major version, newest first.
+	Cycles []MajorCycles `json:"cycles"`
+
+	// Cadence is the number of the releases per year, newest first.
+	Cadence []YearCadence `json:"cadence"`
+
+	// SDKs is how long each SDK version was the newest one bundled with a final release,
+	// ordered by the platform and from the newest version.
+	SDKs []SDKPeriod `json:"sdks"`
+
+	// Swift is the Swift versions bundled with the releases, newest first.
+	Swift []SwiftRelease `json:"swift"`
+}
+
+// BetaCycle is the prerelease period of a minor version, from its first prerelease to its final release.
+type BetaCycle struct {
+	// Version is the major and minor version, e.g. "13.2".
+	Version string `json:"version"`
+
+	// First is the first prerelease, usually the beta 1, and Release is the final release.
+	First   *XcodeRelease `json:"-"`
+	Release *XcodeRelease `json:"-"`
+
+	From  Date `json:"from"`
+	Until Date `json:"until"`
+	Days  int  `json:"days"`
+}
+
+// MajorCycles is the beta cycles of a major version.
+type MajorCycles struct {
+	Major int `json:"major"`
+
+	// Cycles is the beta cycles of the minor versions, newest first.
+	Cycles []BetaCycle `json:"cycles"`
+
+	// AverageDays is the average length of Cycles in days.
+	AverageDays float64 `json:"averageDays"`
+}
+
+// YearCadence is the releases of a year.
+type YearCadence struct {
+	Year int `json:"year"`
+
+	// Releases and Prereleases are the number of the final releases and the prereleases.
+	Releases    int `json:"releases"`
+	Prereleases int `json:"prereleases"`
+
+	// AverageGapDays is the average number of days between the successive final releases of the year,
+	// or 0 if there are less than two.
+	AverageGapDays float64 `json:"averageGapDays"`
+}
+
+// SDKPeriod is the period a SDK version was the newest one bundled with a final release.
+type SDKPeriod struct {
+	Platform Platform `json:"platform"`
+	Version  string   `json:"version"`
+
+	// Xcode is the version of the first final release bundling the SDK.
+	Xcode string `json:"xcode"`
+
+	// From is the date of the first final release bundling the SDK, and Until is the date of the first final
+	// release bundling a newer SDK of the platform, or nil if the SDK is still current.
+	From  Date  `json:"from"`
+	Until *Date `json:"until,omitempty"`
+
+	// Days is the length of the period. The period of the current SDK lasts until the newest release.
+	Days int `json:"days"`
+}
+
+// SwiftRelease is a Swift version bundled with the releases.
+type SwiftRelease struct {
+	Version string `json:"version"`
+
+	// FirstXcode is the version of the first release bundling the Swift version, possibly a prerelease,
+	// and FirstSeen is its date.
+	FirstXcode string `json:"firstXcode"`
+	FirstSeen  Date   `json:"firstSeen"`
+
+	// ReleaseXcode is the version of the first final release bundling the Swift version and Released is its date,
+	// or empty and nil if no final release bundles it yet.
+	ReleaseXcode string `json:"releaseXcode,omitempty"`
+	Released     *Date  `json:"released,omitempty"`
+}
+
+// ComputeStats returns the timeline statistics of xrs.
+//
+// The releases whose version fails to parse are ignored.
+func ComputeStats(xrs []*XcodeRelease) *Stats {
+	ix := NewIndex(xrs)
+
+	// oldest first
+	var releases []*XcodeRelease
+	for i := len(ix.releases) - 1; i >= 0; i-- {
+		if xr := ix.releases[i]; ix.versions[xr] != (XcodeVersion{}) {
+			releases = append(releases, xr)
+		}
+	}
+	sort.SliceStable(releases, func(i, j int) bool {
+		return releases[i].Date.Before(releases[j].Date)
+	})
+
+	return &Stats{
+		Cycles:  betaCycles(ix, releases),
+		Cadence: cadence(ix, releases),
+		SDKs:    sdkPeriods(ix, releases),
+		Swift:   swiftTimeline(ix, releases),
+	}
+}
+
+// daysBetween returns the number of days from a to b.
+func daysBetween(a, b Date) int {
+	return int(b.Time().Sub(a.Time()) / (24 * time.Hour))
+}
+
+func betaCycles(ix *Index, releases []*XcodeRelease) []MajorCycles {
+	type train struct{ major, minor int }
+	first := make(map[train]*XcodeRelease)
+	final := make(map[train]*XcodeRelease)
+	for _, xr := range releases {
+		v := ix.versions[xr]
+		t := train{v.Major, v.Minor}
+		switch {
+		case v.IsPrerelease():
+			if first[t] == nil {
+				first[t] = xr
+			}
+		case v.Patch == 0:
+			if final[t] == nil {
+				final[t] = xr
+			}
+		}
+	}
+
+	byMajor := make(map[int]*MajorCycles)
+	var majors []int
+	for t, f := range first {
+		r := final[t]
+		if r == nil || r.Date.Before(f.Date) {
+			continue
+		}
+		mc := byMajor[t.major]
+		if mc == nil {
+			mc = &MajorCycles{Major: t.major}
+			byMajor[t.major] = mc
+			majors = append(majors, t.major)
+		}
+		mc.Cycles = append(mc.Cycles, BetaCycle{
+			Version: fmt.Sprintf("%d.%d", t.major, t.minor),
+			First:   f,
+			Release: r,
+			From:    f.Date,
+			Until:   r.Date,
+			Days:    daysBetween(f.Date, r.Date),
+		})
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(majors)))
+
+	cycles := make([]MajorCycles, 0, len(majors))
+	for _, m := range majors {
+		mc := byMajor[m]
+		sort.Slice(mc.Cycles, func(i, j int) bool {
+			return ix.versions[mc.Cycles[j].Release].Less(ix.versions[mc.Cycles[i].Release])
+		})
+		var total int
+		for _, c := range mc.Cycles {
+			total += c.Days
+		}
+		mc.AverageDays = float64(total) / float64(len(mc.Cycles))
+		cycles = append(cycles, *mc)
+	}
+
+	return cycles
+}
+
+func cadence(ix *Index, releases []*XcodeRelease) []YearCadence {
+	byYear := make(map[int]*YearCadence)
+	last := make(map[int]Date)
+	gaps := make(map[int]int)
+	var years []int
+	for _, xr := range releases {
+		y := xr.Date.Year
+		yc := byYear[y]
+		if yc == nil {
+			yc = &YearCadence{Year: y}
+			byYear[y] = yc
+			years = append(years, y)
+		}
+		if ix.versions[xr].IsPrerelease() {
+			yc.Prereleases++
+			continue
+		}
+		if yc.Releases > 0 {
+			gaps[y] += daysBetween(last[y], xr.Date)
+		}
+		yc.Releases++
+		last[y] = xr.Date
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(years)))
+
+	out := make([]YearCadence, 0, len(years))
+	for _, y := range years {
+		yc := byYear[y]
+		if yc.Releases > 1 {
+			yc.AverageGapDays = float64(gaps[y]) / float64(yc.Releases-1)
+		}
+		out = append(out, *yc)
+	}
+
+	return out
+}
+
+func sdkPeriods(ix *Index, releases []*XcodeRelease) []SDKPeriod {
+	type first struct {
+		xr   *XcodeRelease
+		date Date
+	}
+	seen := make(map[Platform]map[string]first)
+	var platforms []Platform
+	var newest Date
+	for _, xr := range releases {
+		if ix.versions[xr].IsPrerelease() {
+			continue
+		}
+		newest = xr.Date
+		for _, p := range xr.SDKs.Platforms() {
+			if seen[p] == nil {
+				seen[p] = make(map[string]first)
+				platforms = append(platforms, p)
+			}
+			for _, s := range xr.SDKs.For(p) {
+				if _, ok := seen[p][s.Number]; !ok {
+					seen[p][s.Number] = first{xr, xr.Date}
+				}
+			}
+		}
+	}
+	sortPlatforms(platforms)
+
+	var periods []SDKPeriod
+	for _, p := range platforms {
+		versions := make([]string, 0, len(seen[p]))
+		for v := range seen[p] {
+			versions = append(versions, v)
+		}
+		sort.Slice(versions, func(i, j int) bool { return compareDotted(versions[i], versions[j]) > 0 })
+
+		// The SDK is superseded by the earliest of the newer versions.
+		var until *Date
+		for _, v := range versions {
+			f := seen[p][v]
+			sp := SDKPeriod{
+				Platform: p,
+				Version:  v,
+				Xcode:    f.xr.Title(),
+				From:     f.date,
+				Until:    until,
+			}
+			end := newest
+			if until != nil {
+				end = *until
+			}
+			if d := daysBetween(f.date, end); d > 0 {
+				sp.Days = d
+			}
+			periods = append(periods, sp)
+
+			if until == nil || f.date.Before(*until) {
+				d := f.date
+				until = &d
+			}
+		}
+	}
+
+	return periods
+}
+
+// sortPlatforms sorts ps with the known platforms first.
+func sortPlatforms(ps []Platform) {
+	rank := func(p Platform) int {
+		for i, k := range knownPlatforms {
+			if p == k {
+				return i
+			}
+		}
+		return len(knownPlatforms)
+	}
+	sort.SliceStable(ps, func(i, j int) bool {
+		if ri, rj := rank(ps[i]), rank(ps[j]); ri != rj {
+			return ri < rj
+		}
+		return ps[i] < ps[j]
+	})
+}
+
+func swiftTimeline(ix *Index, releases []*XcodeRelease) []SwiftRelease {
+	bySwift := make(map[string]*SwiftRelease)
+	var versions []string
+	for _, xr := range releases {
+		if xr.Compilers == nil {
+			continue
+		}
+		for _, c := range xr.Compilers.Swift {
+			sr := bySwift[c.Number]
+			if sr == nil {
+				sr = &SwiftRelease{
+					Version:    c.Number,
+					FirstXcode: xr.Title(),
+					FirstSeen:  xr.Date,
+				}
+				bySwift[c.Number] = sr
+				versions = append(versions, c.Number)
+			}
+			if sr.Released == nil && !ix.versions[xr].IsPrerelease() {
+				d := xr.Date
+				sr.ReleaseXcode = xr.Title()
+				sr.Released = &d
+			}
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool { return compareDotted(versions[i], versions[j]) > 0 })
+
+	out := make([]SwiftRelease, 0, len(versions))
+	for _, v := range versions {
+		out = append(out, *bySwift[v])
+	}
+
+	return out
+}
+
+// WriteReport writes s to w as a human readable report.
+func (s *Stats) WriteReport(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "Beta cycles (first prerelease to release)")
+	fmt.Fprintln(tw, "MAJOR\tVERSIONS\tAVERAGE DAYS\tCYCLES")
+	for _, mc := range s.Cycles {
+		cs := make([]string, len(mc.Cycles))
+		for i, c := range mc.Cycles {
+			cs[i] = fmt.Sprintf("%s: %dd", c.Version, c.Days)
+		}
+		fmt.Fprintf(tw, "%d\t%d\t%.1f\t%s\n", mc.Major, len(mc.Cycles), mc.AverageDays, strings.Join(cs, ", "))
+	}
+
+	fmt.Fprintln(tw, "\nRelease cadence")
+	fmt.Fprintln(tw, "YEAR\tRELEASES\tPRERELEASES\tAVERAGE GAP DAYS")
+	for _, yc := range s.Cadence {
+		gap := "-"
+		if yc.Releases > 1 {
+			gap = fmt.Sprintf("%.1f", yc.AverageGapDays)
+		}
+		fmt.Fprintf(tw, "%d\t%d\t%d\t%s\n", yc.Year, yc.Releases, yc.Prereleases, gap)
+	}
+
+	fmt.Fprintln(tw, "\nSDK currency")
+	fmt.Fprintln(tw, "PLATFORM\tSDK\tFIRST XCODE\tFROM\tUNTIL\tDAYS")
+	for _, sp := range s.SDKs {
+		until := "current"
+		if sp.Until != nil {
+			until = sp.Until.String()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%d\n", sp.Platform, sp.Version, sp.Xcode, sp.From, until, sp.Days)
+	}
+
+	fmt.Fprintln(tw, "\nSwift timeline")
+	fmt.Fprintln(tw, "SWIFT\tFIRST XCODE\tFIRST SEEN\tRELEASE XCODE\tRELEASED")
+	for _, sr := range s.Swift {
+		releaseXcode, released := "-", "-"
+		if sr.Released != nil {
+			releaseXcode, released = sr.ReleaseXcode, sr.Released.String()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", sr.Version, sr.FirstXcode, sr.FirstSeen, releaseXcode, released)
+	}
+
+	return tw.Flush()
+}
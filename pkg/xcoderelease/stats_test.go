@@ -0,0 +1,176 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testStatsReleases returns the releases of 2021 and 2022 with their macOS SDK and Swift versions.
+func testStatsReleases(t *testing.T) []*XcodeRelease {
+	t.Helper()
+
+	entries := []struct {
+		version string
+		date    Date
+		macOS   string
+		swift   string
+	}{
+		{"14.0 Beta 1 (14A5228q)", Date{Day: 6, Month: 6, Year: 2022}, "13.0", "5.7"},
+		{"13.2.1 (13C100)", Date{Day: 17, Month: 12, Year: 2021}, "12.1", "5.5.2"},
+		{"13.2 Beta 1 (13C5066c)", Date{Day: 27, Month: 10, Year: 2021}, "12.1", "5.5.2"},
+		{"13.1 (13A1030d)", Date{Day: 25, Month: 10, Year: 2021}, "12.0", "5.5.1"},
+		{"13.1 RC (13A1030d)", Date{Day: 18, Month: 10, Year: 2021}, "12.0", "5.5.1"},
+		{"13.0 (13A233)", Date{Day: 20, Month: 9, Year: 2021}, "11.3", "5.5"},
+		{"13.0 Beta 1 (13A5154h)", Date{Day: 7, Month: 6, Year: 2021}, "12.0", "5.5"},
+		{"12.5 (12E262)", Date{Day: 26, Month: 4, Year: 2021}, "11.3", "5.4"},
+		{"12.5 Beta 1 (12E5220o)", Date{Day: 1, Month: 2, Year: 2021}, "11.3", "5.4"},
+	}
+
+	xrs := make([]*XcodeRelease, len(entries))
+	for i, e := range entries {
+		xr := testRelease(t, e.version)
+		xr.Date = e.date
+		xr.SDKs = &SDKs{MacOS: []SDK{{Number: e.macOS}}}
+		xr.Compilers = &Compilers{Swift: []SwiftCompiler{{Number: e.swift}}}
+		xrs[i] = xr
+	}
+
+	return xrs
+}
+
+func TestComputeStatsCycles(t *testing.T) {
+	s := ComputeStats(testStatsReleases(t))
+
+	type cycle struct {
+		version string
+		days    int
+	}
+	type major struct {
+		major   int
+		cycles  []cycle
+		average float64
+	}
+	// 13.2 and 14.0 have no final x.y.0 release yet
+	want := []major{
+		{major: 13, cycles: []cycle{{"13.1", 7}, {"13.0", 105}}, average: 56},
+		{major: 12, cycles: []cycle{{"12.5", 84}}, average: 84},
+	}
+
+	var got []major
+	for _, mc := range s.Cycles {
+		m := major{major: mc.Major, average: mc.AverageDays}
+		for _, c := range mc.Cycles {
+			m.cycles = append(m.cycles, cycle{c.Version, c.Days})
+		}
+		got = append(got, m)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Cycles = %+v, want %+v", got, want)
+	}
+
+	c := s.Cycles[0].Cycles[1]
+	if c.First.Version.Build != "13A5154h" || c.Release.Version.Build != "13A233" {
+		t.Errorf("Cycles 13.0 = %s to %s", releaseTitle(c.First), releaseTitle(c.Release))
+	}
+	if c.From != (Date{Day: 7, Month: 6, Year: 2021}) || c.Until != (Date{Day: 20, Month: 9, Year: 2021}) {
+		t.Errorf("Cycles 13.0 = %s to %s", c.From, c.Until)
+	}
+}
+
+func TestComputeStatsCadence(t *testing.T) {
+	s := ComputeStats(testStatsReleases(t))
+
+	want := []YearCadence{
+		{Year: 2022, Releases: 0, Prereleases: 1},
+		{Year: 2021, Releases: 4, Prereleases: 4, AverageGapDays: float64(147+35+53) / 3},
+	}
+	if !reflect.DeepEqual(s.Cadence, want) {
+		t.Errorf("Cadence = %+v, want %+v", s.Cadence, want)
+	}
+}
+
+func TestComputeStatsSDKs(t *testing.T) {
+	s := ComputeStats(testStatsReleases(t))
+
+	oct25 := Date{Day: 25, Month: 10, Year: 2021}
+	dec17 := Date{Day: 17, Month: 12, Year: 2021}
+	// the prereleases do not count, so macOS 13.0 is not listed
+	want := []SDKPeriod{
+		{Platform: PlatformMacOS, Version: "12.1", Xcode: "13.2.1", From: dec17},
+		{Platform: PlatformMacOS, Version: "12.0", Xcode: "13.1", From: oct25, Until: &dec17, Days: 53},
+		{Platform: PlatformMacOS, Version: "11.3", Xcode: "12.5", From: Date{Day: 26, Month: 4, Year: 2021}, Until: &oct25, Days: 182},
+	}
+	if !reflect.DeepEqual(s.SDKs, want) {
+		t.Errorf("SDKs = %+v, want %+v", s.SDKs, want)
+	}
+}
+
+func TestComputeStatsSwift(t *testing.T) {
+	s := ComputeStats(testStatsReleases(t))
+
+	date := func(day, month, year int) *Date {
+		return &Date{Day: day, Month: month, Year: year}
+	}
+	want := []SwiftRelease{
+		{Version: "5.7", FirstXcode: "14.0 Beta 1", FirstSeen: *date(6, 6, 2022)},
+		{Version: "5.5.2", FirstXcode: "13.2 Beta 1", FirstSeen: *date(27, 10, 2021), ReleaseXcode: "13.2.1", Released: date(17, 12, 2021)},
+		{Version: "5.5.1", FirstXcode: "13.1 RC", FirstSeen: *date(18, 10, 2021), ReleaseXcode: "13.1", Released: date(25, 10, 2021)},
+		{Version: "5.5", FirstXcode: "13.0 Beta 1", FirstSeen: *date(7, 6, 2021), ReleaseXcode: "13.0", Released: date(20, 9, 2021)},
+		{Version: "5.4", FirstXcode: "12.5 Beta 1", FirstSeen: *date(1, 2, 2021), ReleaseXcode: "12.5", Released: date(26, 4, 2021)},
+	}
+	if !reflect.DeepEqual(s.Swift, want) {
+		t.Errorf("Swift = %+v, want %+v", s.Swift, want)
+	}
+}
+
+func TestComputeStatsEmpty(t *testing.T) {
+	s := ComputeStats(nil)
+	if len(s.Cycles) != 0 || len(s.Cadence) != 0 || len(s.SDKs) != 0 || len(s.Swift) != 0 {
+		t.Errorf("ComputeStats(nil) = %+v, want empty", s)
+	}
+
+	var buf bytes.Buffer
+	if err := s.WriteReport(&buf); err != nil {
+		t.Errorf("WriteReport() error = %v", err)
+	}
+}
+
+type errWriter struct{ err error }
+
+func (w errWriter) Write([]byte) (int, error) { return 0, w.err }
+
+func TestStatsWriteReport(t *testing.T) {
+	s := ComputeStats(testStatsReleases(t))
+
+	var buf bytes.Buffer
+	if err := s.WriteReport(&buf); err != nil {
+		t.Fatalf("WriteReport() error = %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"Beta cycles (first prerelease to release)",
+		"13     2         56.0          13.1: 7d, 13.0: 105d",
+		"Release cadence",
+		"2021  4         4            78.3",
+		"2022  0         1            -",
+		"SDK currency",
+		"macOS     12.1  13.2.1       2021-12-17  current     0",
+		"Swift timeline",
+		"5.7    14.0 Beta 1  2022-06-06  -              -",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("WriteReport() =\n%s\nwant the line %q", out, want)
+		}
+	}
+
+	errWrite := errors.New("write")
+	if err := s.WriteReport(errWriter{errWrite}); !errors.Is(err, errWrite) {
+		t.Errorf("WriteReport() error = %v, want %v", err, errWrite)
+	}
+}
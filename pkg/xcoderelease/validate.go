@@ -0,0 +1,300 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Severity represents how suspicious a Finding is.
+type Severity int
+
+// List of severities.
+const (
+	// SeverityError is a mistake in the data, such as a malformed value.
+	SeverityError Severity = iota
+	// SeverityWarning is a likely mistake or a gap in the data, such as a missing checksum.
+	SeverityWarning
+)
+
+// String implements fmt.Stringer.
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Rule identifies a check of Validate.
+type Rule string
+
+// List of rules.
+const (
+	RuleNilRelease     Rule = "nil-release"
+	RuleNoSDKs         Rule = "no-sdks"
+	RuleBadVersion     Rule = "bad-version"
+	RuleBadBuild       Rule = "bad-build"
+	RuleDuplicateBuild Rule = "duplicate-build"
+	RuleDateOrder      Rule = "date-order"
+	RuleReleaseFlag    Rule = "release-flag"
+	RuleNoChecksum     Rule = "no-checksum"
+	RuleBadChecksum    Rule = "bad-checksum"
+	RuleBadURL         Rule = "bad-url"
+)
+
+// Finding is a suspicious entry reported by Validate.
+type Finding struct {
+	// Path is the location of the entry in the releases, e.g. "$[3].version.build".
+	Path string `json:"path"`
+
+	// Index is the index of the release in the validated releases.
+	Index int `json:"index"`
+
+	Rule     Rule     `json:"rule"`
+	Severity Severity `json:"severity"`
+
+	// Message describes the finding.
+	Message string `json:"message"`
+
+	// Release is the release the finding is about, or nil for RuleNilRelease.
+	Release *XcodeRelease `json:"-"`
+}
+
+// String returns the located message of f.
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s: %s (%s)", f.Path, f.Severity, f.Message, f.Rule)
+}
+
+// validator collects the findings of Validate.
+type validator struct {
+	xrs      []*XcodeRelease
+	findings []Finding
+}
+
+func (v *validator) report(i int, field string, rule Rule, sev Severity, format string, args ...interface{}) {
+	path := fmt.Sprintf("$[%d]", i)
+	if field != "" {
+		path += "." + field
+	}
+
+	v.findings = append(v.findings, Finding{
+		Path:     path,
+		Index:    i,
+		Rule:     rule,
+		Severity: sev,
+		Message:  fmt.Sprintf(format, args...),
+		Release:  v.xrs[i],
+	})
+}
+
+// Validate reports the suspicious entries of xrs, ordered by the index of the release.
+// It returns nil if it finds none.
+//
+// It reports the releases without SDKs, the version and build numbers which fail to parse, the builds shared by
+// releases other than a prerelease and the final release built from it, the releases dated before the next older
+// version of the same major and minor version, the release flags contradicting the prerelease numbers,
+// the final releases without a checksum, the malformed checksums and the malformed links.
+func Validate(xrs []*XcodeRelease) []Finding {
+	v := &validator{xrs: xrs}
+
+	for i, xr := range xrs {
+		if xr == nil {
+			v.report(i, "", RuleNilRelease, SeverityError, "release is null")
+			continue
+		}
+		v.checkRelease(i, xr)
+	}
+	v.checkDuplicateBuilds()
+	v.checkDateOrder()
+
+	sort.SliceStable(v.findings, func(i, j int) bool {
+		return v.findings[i].Index < v.findings[j].Index
+	})
+
+	return v.findings
+}
+
+func (v *validator) checkRelease(i int, xr *XcodeRelease) {
+	if len(xr.SDKs.Platforms()) == 0 {
+		v.report(i, "sdks", RuleNoSDKs, SeverityWarning, "release lists no SDKs")
+	}
+
+	if _, _, _, err := parseNumber(xr.Version.Number); err != nil {
+		v.report(i, "version.number", RuleBadVersion, SeverityError, "%v", err)
+	}
+	if _, err := ParseBuild(xr.Version.Build); err != nil {
+		v.report(i, "version.build", RuleBadBuild, SeverityError, "%v", err)
+	}
+	for _, p := range xr.SDKs.Platforms() {
+		for j, s := range xr.SDKs.For(p) {
+			if s.Build == "" {
+				continue
+			}
+			if _, err := ParseBuild(s.Build); err != nil {
+				v.report(i, fmt.Sprintf("sdks.%s[%d].build", p, j), RuleBadBuild, SeverityWarning, "%v", err)
+			}
+		}
+	}
+
+	v.checkReleaseFlag(i, xr.Version.Release)
+
+	switch sha1 := xr.Checksums.Sha1; {
+	case sha1 == "":
+		if xr.Version.Channel() == ChannelRelease {
+			v.report(i, "checksums.sha1", RuleNoChecksum, SeverityWarning, "final release has no checksum")
+		}
+	case len(sha1) != 40 || strings.Trim(strings.ToLower(sha1), "0123456789abcdef") != "":
+		v.report(i, "checksums.sha1", RuleBadChecksum, SeverityError, "malformed SHA-1 %q", sha1)
+	}
+
+	for _, l := range []struct {
+		field string
+		url   string
+	}{
+		{"links.download.url", xr.Links.Download.URL},
+		{"links.notes.url", xr.Links.Notes.URL},
+	} {
+		if l.url == "" {
+			continue
+		}
+		if err := checkURL(l.url); err != nil {
+			v.report(i, l.field, RuleBadURL, SeverityError, "%v", err)
+		}
+	}
+}
+
+// checkReleaseFlag reports the release flag of r contradicting its prerelease numbers.
+func (v *validator) checkReleaseFlag(i int, r *Release) {
+	if r == nil {
+		return
+	}
+
+	var pre []string
+	for _, n := range []struct {
+		name string
+		set  bool
+	}{
+		{"dp", r.Dp > 0},
+		{"beta", r.Beta > 0},
+		{"gmSeed", r.GmSeed > 0},
+		{"gm", r.Gm},
+		{"rc", r.Rc > 0},
+	} {
+		if n.set {
+			pre = append(pre, n.name)
+		}
+	}
+
+	switch {
+	case r.Release && len(pre) > 0:
+		v.report(i, "version.release", RuleReleaseFlag, SeverityError, "final release has the prerelease %s", strings.Join(pre, ", "))
+	case !r.Release && len(pre) == 0:
+		v.report(i, "version.release", RuleReleaseFlag, SeverityError, "prerelease has no dp, beta, gmSeed, gm or rc")
+	case len(pre) > 1:
+		v.report(i, "version.release", RuleReleaseFlag, SeverityWarning, "prerelease has several of %s", strings.Join(pre, ", "))
+	}
+}
+
+func checkURL(s string) error {
+	u, err := url.Parse(s)
+	if err != nil {
+		return fmt.Errorf("malformed URL %q: %w", s, err)
+	}
+	if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("malformed URL %q: not an absolute HTTP URL", s)
+	}
+	if strings.ContainsAny(s, " \t\n") {
+		return fmt.Errorf("malformed URL %q: contains spaces", s)
+	}
+
+	return nil
+}
+
+// checkDuplicateBuilds reports the releases sharing a build, except a prerelease and the final release of the same
+// version, such as a RC promoted to the final release.
+func (v *validator) checkDuplicateBuilds() {
+	byBuild := make(map[string][]int)
+	var builds []string
+	for i, xr := range v.xrs {
+		if xr == nil || xr.Version.Build == "" {
+			continue
+		}
+		b := xr.Version.Build
+		if _, ok := byBuild[b]; !ok {
+			builds = append(builds, b)
+		}
+		byBuild[b] = append(byBuild[b], i)
+	}
+
+	for _, b := range builds {
+		is := byBuild[b]
+		if len(is) < 2 || isPromotion(v.xrs[is[0]], v.xrs[is[1]]) && len(is) == 2 {
+			continue
+		}
+		for _, i := range is[1:] {
+			v.report(i, "version.build", RuleDuplicateBuild, SeverityError, "build %s is also listed at $[%d]", b, is[0])
+		}
+	}
+}
+
+// isPromotion reports whether a and b are a prerelease and the final release built from it.
+func isPromotion(a, b *XcodeRelease) bool {
+	if a.Version.Number != b.Version.Number {
+		return false
+	}
+
+	return (a.Version.Channel() == ChannelRelease) != (b.Version.Channel() == ChannelRelease)
+}
+
+// checkDateOrder reports the releases dated before the next older version of the same major and minor version.
+//
+// The different minor versions are not compared since Apple ships the updates of the older versions
+// while the betas of the next one are out.
+func (v *validator) checkDateOrder() {
+	type entry struct {
+		i int
+		v XcodeVersion
+	}
+	trains := make(map[[2]int][]entry)
+	var keys [][2]int
+	for i, xr := range v.xrs {
+		if xr == nil {
+			continue
+		}
+		xv, err := xr.Version.XcodeVersion()
+		if err != nil {
+			continue
+		}
+		k := [2]int{xv.Major, xv.Minor}
+		if _, ok := trains[k]; !ok {
+			keys = append(keys, k)
+		}
+		trains[k] = append(trains[k], entry{i, xv})
+	}
+
+	for _, k := range keys {
+		es := trains[k]
+		sort.SliceStable(es, func(i, j int) bool { return es[i].v.Less(es[j].v) })
+
+		for j := 1; j < len(es); j++ {
+			older, newer := v.xrs[es[j-1].i], v.xrs[es[j].i]
+			if newer.Date.Before(older.Date) {
+				v.report(es[j].i, "date", RuleDateOrder, SeverityWarning, "%s is dated %s, before the older %s dated %s",
+					releaseTitle(newer), newer.Date, releaseTitle(older), older.Date)
+			}
+		}
+	}
+}
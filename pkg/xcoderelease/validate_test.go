@@ -0,0 +1,136 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"strings"
+	"testing"
+)
+
+// validRelease returns the release of testRelease which Validate reports nothing about.
+func validRelease(t *testing.T, s string, date Date) *XcodeRelease {
+	t.Helper()
+
+	xr := testRelease(t, s)
+	xr.Date = date
+	xr.SDKs = new(SDKs)
+	xr.SDKs.Set(PlatformMacOS, []SDK{{Build: "21C46", Number: "12.1", Release: true}})
+	xr.Links.Download.URL = "https://download.developer.apple.com/Developer_Tools/Xcode_" + xr.Version.Number + "/Xcode_" + xr.Version.Number + ".xip"
+	if xr.Version.Channel() == ChannelRelease {
+		xr.Checksums.Sha1 = strings.Repeat("ab", 20)
+	}
+
+	return xr
+}
+
+func TestValidateClean(t *testing.T) {
+	xrs := []*XcodeRelease{
+		validRelease(t, "13.2.1 (13C100)", Date{Year: 2021, Month: 12, Day: 17}),
+		// a RC promoted to the final release shares the build
+		validRelease(t, "13.2 (13C90)", Date{Year: 2021, Month: 12, Day: 13}),
+		validRelease(t, "13.2 RC (13C90)", Date{Year: 2021, Month: 12, Day: 7}),
+		validRelease(t, "13.2 Beta 2 (13C5066c)", Date{Year: 2021, Month: 11, Day: 16}),
+		// the update of the older version released while the next one is in beta
+		validRelease(t, "13.1 (13A1030d)", Date{Year: 2021, Month: 12, Day: 20}),
+	}
+
+	if fs := Validate(xrs); fs != nil {
+		t.Errorf("Validate() = %v, want nil", fs)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	date := Date{Year: 2021, Month: 12, Day: 13}
+
+	tests := []struct {
+		name   string
+		modify func(xr *XcodeRelease)
+		path   string
+		rule   Rule
+		sev    Severity
+	}{
+		{name: "NoSDKs", modify: func(xr *XcodeRelease) { xr.SDKs = nil }, path: "$[0].sdks", rule: RuleNoSDKs, sev: SeverityWarning},
+		{name: "BadVersion", modify: func(xr *XcodeRelease) { xr.Version.Number = "13.x" }, path: "$[0].version.number", rule: RuleBadVersion, sev: SeverityError},
+		{name: "BadBuild", modify: func(xr *XcodeRelease) { xr.Version.Build = "13-C90" }, path: "$[0].version.build", rule: RuleBadBuild, sev: SeverityError},
+		{
+			name:   "BadSDKBuild",
+			modify: func(xr *XcodeRelease) { xr.SDKs.Set(PlatformIOS, []SDK{{Build: "19C51"}, {Build: "bad"}}) },
+			path:   "$[0].sdks.iOS[1].build", rule: RuleBadBuild, sev: SeverityWarning,
+		},
+		{
+			name:   "ReleaseWithPrerelease",
+			modify: func(xr *XcodeRelease) { xr.Version.Release.Beta = 2 },
+			path:   "$[0].version.release", rule: RuleReleaseFlag, sev: SeverityError,
+		},
+		{
+			name:   "PrereleaseWithoutChannel",
+			modify: func(xr *XcodeRelease) { xr.Version.Release = &Release{} },
+			path:   "$[0].version.release", rule: RuleReleaseFlag, sev: SeverityError,
+		},
+		{
+			name:   "SeveralChannels",
+			modify: func(xr *XcodeRelease) { xr.Version.Release = &Release{Beta: 2, Rc: 1} },
+			path:   "$[0].version.release", rule: RuleReleaseFlag, sev: SeverityWarning,
+		},
+		{name: "NoChecksum", modify: func(xr *XcodeRelease) { xr.Checksums.Sha1 = "" }, path: "$[0].checksums.sha1", rule: RuleNoChecksum, sev: SeverityWarning},
+		{name: "BadChecksum", modify: func(xr *XcodeRelease) { xr.Checksums.Sha1 = "xyz" }, path: "$[0].checksums.sha1", rule: RuleBadChecksum, sev: SeverityError},
+		{
+			name:   "RelativeURL",
+			modify: func(xr *XcodeRelease) { xr.Links.Download.URL = "/Xcode_13.2.xip" },
+			path:   "$[0].links.download.url", rule: RuleBadURL, sev: SeverityError,
+		},
+		{
+			name:   "URLWithSpace",
+			modify: func(xr *XcodeRelease) { xr.Links.Notes.URL = "https://developer.apple.com/release notes" },
+			path:   "$[0].links.notes.url", rule: RuleBadURL, sev: SeverityError,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			xr := validRelease(t, "13.2 (13C90)", date)
+			tt.modify(xr)
+
+			fs := Validate([]*XcodeRelease{xr})
+			if len(fs) != 1 {
+				t.Fatalf("Validate() = %v, want a finding", fs)
+			}
+			f := fs[0]
+			if f.Path != tt.path || f.Rule != tt.rule || f.Severity != tt.sev || f.Index != 0 || f.Release != xr {
+				t.Errorf("Validate() = %v, want %s %s %s", f, tt.path, tt.sev, tt.rule)
+			}
+		})
+	}
+}
+
+func TestValidateAcrossReleases(t *testing.T) {
+	xrs := []*XcodeRelease{
+		validRelease(t, "13.2.1 (13C100)", Date{Year: 2021, Month: 12, Day: 17}),
+		nil,
+		validRelease(t, "13.2 (13C100)", Date{Year: 2021, Month: 12, Day: 13}),
+		validRelease(t, "13.2 Beta 2 (13C5066c)", Date{Year: 2021, Month: 12, Day: 20}),
+	}
+
+	want := []struct {
+		path string
+		rule Rule
+	}{
+		{"$[1]", RuleNilRelease},
+		{"$[2].version.build", RuleDuplicateBuild},
+		{"$[2].date", RuleDateOrder},
+	}
+
+	fs := Validate(xrs)
+	if len(fs) != len(want) {
+		t.Fatalf("Validate() = %v, want %d findings", fs, len(want))
+	}
+	for i, w := range want {
+		if fs[i].Path != w.path || fs[i].Rule != w.rule {
+			t.Errorf("Validate()[%d] = %v, want %s %s", i, fs[i], w.path, w.rule)
+		}
+	}
+	if !strings.Contains(fs[1].Message, "$[0]") {
+		t.Errorf("duplicate build message %q does not point at $[0]", fs[1].Message)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+var (
+	// ErrNoChecksum is returned when the release has no checksum to verify against.
+	ErrNoChecksum = errors.New("release has no checksum")
+
+	// ErrUnknownChecksum is returned when no release has the checksum of the file.
+	ErrUnknownChecksum = errors.New("no release has the checksum")
+)
+
+// ChecksumMismatchError is returned when the digest of the file does not match the release checksum.
+type ChecksumMismatchError struct {
+	// Algorithm is the digest algorithm, e.g. "sha1".
+	Algorithm string
+
+	// Want is the checksum of the release, and Got is the digest of the file, in hex.
+	Want string
+	Got  string
+
+	// Release is the release verified against.
+	Release *XcodeRelease
+}
+
+// Error implements error.
+func (e *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("%s checksum mismatch for %s: want %s, got %s", e.Algorithm, releaseTitle(e.Release), e.Want, e.Got)
+}
+
+// Digests is the hex encoded digests of an archive.
+type Digests struct {
+	SHA1   string
+	SHA256 string
+
+	// Size is the size of the archive in bytes.
+	Size int64
+}
+
+// ComputeDigests reads r to the end and returns its digests.
+func ComputeDigests(r io.Reader) (Digests, error) {
+	h1, h256 := sha1.New(), sha256.New()
+
+	n, err := io.Copy(io.MultiWriter(h1, h256), r)
+	if err != nil {
+		return Digests{}, err
+	}
+
+	return Digests{
+		SHA1:   hex.EncodeToString(h1.Sum(nil)),
+		SHA256: hex.EncodeToString(h256.Sum(nil)),
+		Size:   n,
+	}, nil
+}
+
+// ComputeFileDigests returns the digests of the file at path.
+func ComputeFileDigests(path string) (Digests, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Digests{}, err
+	}
+	defer f.Close()
+
+	d, err := ComputeDigests(f)
+	if err != nil {
+		return Digests{}, fmt.Errorf("compute digests of %s: %w", path, err)
+	}
+
+	return d, nil
+}
+
+// Verify checks d against the checksums of xr.
+//
+// It returns ErrNoChecksum if xr has no checksum, or *ChecksumMismatchError if a digest does not match.
+func (xr *XcodeRelease) Verify(d Digests) error {
+	want := strings.TrimSpace(xr.Checksums.Sha1)
+	if want == "" {
+		return fmt.Errorf("verify %s: %w", releaseTitle(xr), ErrNoChecksum)
+	}
+	if !strings.EqualFold(want, d.SHA1) {
+		return &ChecksumMismatchError{Algorithm: "sha1", Want: strings.ToLower(want), Got: d.SHA1, Release: xr}
+	}
+
+	return nil
+}
+
+// VerifyFile streams the archive at path, such as a downloaded .xip, and checks it against the checksums of xr.
+func VerifyFile(path string, xr *XcodeRelease) error {
+	if strings.TrimSpace(xr.Checksums.Sha1) == "" {
+		return fmt.Errorf("verify %s: %w", releaseTitle(xr), ErrNoChecksum)
+	}
+
+	d, err := ComputeFileDigests(path)
+	if err != nil {
+		return err
+	}
+
+	return xr.Verify(d)
+}
+
+// FindByChecksum returns the release whose SHA1 checksum is sha1, or nil if there is none.
+func (ix *Index) FindByChecksum(sha1 string) *XcodeRelease {
+	return ix.bySHA1[strings.ToLower(strings.TrimSpace(sha1))]
+}
+
+// Identify returns the release of the archive at path by looking up its checksum.
+//
+// It returns ErrUnknownChecksum if no release has the checksum.
+func (ix *Index) Identify(path string) (*XcodeRelease, Digests, error) {
+	d, err := ComputeFileDigests(path)
+	if err != nil {
+		return nil, Digests{}, err
+	}
+
+	xr := ix.FindByChecksum(d.SHA1)
+	if xr == nil {
+		return nil, d, fmt.Errorf("identify %s: sha1 %s: %w", path, d.SHA1, ErrUnknownChecksum)
+	}
+
+	return xr, d, nil
+}
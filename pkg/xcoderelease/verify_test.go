@@ -0,0 +1,130 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	// testXipSHA1 and testXipSHA256 are the digests of "abc".
+	testXipSHA1   = "a9993e364706816aba3e25717850c26c9cd0d89d"
+	testXipSHA256 = "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"
+)
+
+// testXip writes the archive "abc" and returns its path.
+func testXip(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "Xcode_13.2.1.xip")
+	if err := os.WriteFile(path, []byte("abc"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestComputeDigests(t *testing.T) {
+	d, err := ComputeDigests(strings.NewReader("abc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Digests{SHA1: testXipSHA1, SHA256: testXipSHA256, Size: 3}); d != want {
+		t.Errorf("ComputeDigests() = %+v, want %+v", d, want)
+	}
+}
+
+func TestVerifyFile(t *testing.T) {
+	path := testXip(t)
+
+	tests := []struct {
+		name string
+		sha1 string
+	}{
+		{name: "Lower", sha1: testXipSHA1},
+		{name: "Upper", sha1: strings.ToUpper(testXipSHA1)},
+		{name: "Spaces", sha1: " " + testXipSHA1 + "\n"},
+	}
+	for _, tt := range tests {
+		xr := testRelease(t, "13.2.1 (13C100)")
+		xr.Checksums.Sha1 = tt.sha1
+		if err := VerifyFile(path, xr); err != nil {
+			t.Errorf("%s: VerifyFile() error = %v", tt.name, err)
+		}
+	}
+}
+
+func TestVerifyFileMismatch(t *testing.T) {
+	const want = "0123456789abcdef0123456789abcdef01234567"
+
+	xr := testRelease(t, "13.2.1 (13C100)")
+	xr.Checksums.Sha1 = strings.ToUpper(want)
+
+	err := VerifyFile(testXip(t), xr)
+
+	var merr *ChecksumMismatchError
+	if !errors.As(err, &merr) {
+		t.Fatalf("VerifyFile() error = %v, want *ChecksumMismatchError", err)
+	}
+	if merr.Algorithm != "sha1" || merr.Want != want || merr.Got != testXipSHA1 || merr.Release != xr {
+		t.Errorf("ChecksumMismatchError = %+v", merr)
+	}
+	if got, want := err.Error(), "sha1 checksum mismatch for 13.2.1 (13C100): want "+want+", got "+testXipSHA1; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestVerifyNoChecksum(t *testing.T) {
+	xr := testRelease(t, "13.2.1 (13C100)")
+
+	if err := xr.Verify(Digests{SHA1: testXipSHA1}); !errors.Is(err, ErrNoChecksum) {
+		t.Errorf("Verify() error = %v, want %v", err, ErrNoChecksum)
+	}
+
+	// the file is not read without a checksum
+	if err := VerifyFile(filepath.Join(t.TempDir(), "missing.xip"), xr); !errors.Is(err, ErrNoChecksum) {
+		t.Errorf("VerifyFile() error = %v, want %v", err, ErrNoChecksum)
+	}
+}
+
+func TestIdentify(t *testing.T) {
+	xrs := testReleases(t, "13.2.1 (13C100)", "13.2 (13C90)", "13.1 (13A1030d)")
+	xrs[0].Checksums.Sha1 = "0123456789abcdef0123456789abcdef01234567"
+	xrs[1].Checksums.Sha1 = strings.ToUpper(testXipSHA1)
+	ix := NewIndex(xrs)
+
+	xr, d, err := ix.Identify(testXip(t))
+	if err != nil {
+		t.Fatalf("Identify() error = %v", err)
+	}
+	if xr != xrs[1] {
+		t.Errorf("Identify() = %s, want 13C90", releaseTitle(xr))
+	}
+	if d.SHA1 != testXipSHA1 || d.SHA256 != testXipSHA256 || d.Size != 3 {
+		t.Errorf("Identify() digests = %+v", d)
+	}
+
+	if got := ix.FindByChecksum(" " + testXipSHA1); got != xrs[1] {
+		t.Errorf("FindByChecksum() = %v, want 13C90", got)
+	}
+	if got := ix.FindByChecksum(""); got != nil {
+		t.Errorf("FindByChecksum(empty) = %s, want nil", releaseTitle(got))
+	}
+}
+
+func TestIdentifyUnknown(t *testing.T) {
+	ix := NewIndex(testReleases(t, "13.2.1 (13C100)"))
+
+	xr, d, err := ix.Identify(testXip(t))
+	if !errors.Is(err, ErrUnknownChecksum) {
+		t.Fatalf("Identify() = %v, %v, want %v", xr, err, ErrUnknownChecksum)
+	}
+	if d.SHA1 != testXipSHA1 {
+		t.Errorf("Identify() digests = %+v, want the digests of the file", d)
+	}
+}
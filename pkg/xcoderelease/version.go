@@ -0,0 +1,318 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Channel represents a release channel of Xcode.
+type Channel int
+
+const (
+	// ChannelRelease is the final release.
+	ChannelRelease Channel = iota
+	// ChannelDP is the developer preview.
+	ChannelDP
+	// ChannelBeta is the beta release.
+	ChannelBeta
+	// ChannelGMSeed is the GM seed.
+	ChannelGMSeed
+	// ChannelGM is the golden master.
+	ChannelGM
+	// ChannelRC is the release candidate.
+	ChannelRC
+)
+
+// rank returns the order of ch, where dp < beta < gmSeed < gm == rc < release.
+func (ch Channel) rank() int {
+	switch ch {
+	case ChannelDP:
+		return 1
+	case ChannelBeta:
+		return 2
+	case ChannelGMSeed:
+		return 3
+	case ChannelGM, ChannelRC:
+		return 4
+	case ChannelRelease:
+		return 5
+	default:
+		return 0
+	}
+}
+
+// String returns the data.json key of ch, e.g. "beta" or "gmSeed".
+func (ch Channel) String() string {
+	switch ch {
+	case ChannelRelease:
+		return "release"
+	case ChannelDP:
+		return "dp"
+	case ChannelBeta:
+		return "beta"
+	case ChannelGMSeed:
+		return "gmSeed"
+	case ChannelGM:
+		return "gm"
+	case ChannelRC:
+		return "rc"
+	default:
+		return fmt.Sprintf("Channel(%d)", int(ch))
+	}
+}
+
+// title returns the human readable name of ch, e.g. "Beta" or "GM Seed".
+func (ch Channel) title() string {
+	switch ch {
+	case ChannelDP:
+		return "DP"
+	case ChannelBeta:
+		return "Beta"
+	case ChannelGMSeed:
+		return "GM Seed"
+	case ChannelGM:
+		return "GM"
+	case ChannelRC:
+		return "RC"
+	default:
+		return ""
+	}
+}
+
+// ParseChannel parses the channel name s case-insensitively.
+//
+// It accepts the data.json keys such as "beta" or "gmSeed" and the human readable names such as "GM Seed".
+func ParseChannel(s string) (Channel, error) {
+	switch strings.ToLower(strings.Join(strings.Fields(s), " ")) {
+	case "release", "stable", "final":
+		return ChannelRelease, nil
+	case "dp", "developer preview":
+		return ChannelDP, nil
+	case "beta":
+		return ChannelBeta, nil
+	case "gmseed", "gm seed":
+		return ChannelGMSeed, nil
+	case "gm":
+		return ChannelGM, nil
+	case "rc", "release candidate":
+		return ChannelRC, nil
+	default:
+		return 0, fmt.Errorf("unknown channel %q", s)
+	}
+}
+
+// XcodeVersion represents a parsed, comparable Xcode version.
+type XcodeVersion struct {
+	Major int
+	Minor int
+	Patch int
+
+	// Channel is the release channel.
+	Channel Channel
+
+	// Pre is the number of the pre-release, e.g. 2 of "Beta 2". It is zero for GM and final releases.
+	Pre int
+
+	// Build is the build identifier, e.g. "13C5081f". It does not take part in the comparison.
+	Build string
+}
+
+// ParseXcodeVersion parses s in the form returned by XcodeVersion.String, e.g. "13.2 Beta 2 (13C5081f)".
+//
+// The channel and the build are optional, and the channel name is case-insensitive.
+// An unnumbered pre-release such as "13.0 RC" is the first one.
+func ParseXcodeVersion(s string) (XcodeVersion, error) {
+	var v XcodeVersion
+
+	rest := strings.TrimSpace(s)
+	if i := strings.IndexByte(rest, '('); i >= 0 {
+		if !strings.HasSuffix(rest, ")") {
+			return XcodeVersion{}, fmt.Errorf("parse Xcode version %q: unterminated build", s)
+		}
+		v.Build = strings.TrimSpace(rest[i+1 : len(rest)-1])
+		rest = strings.TrimSpace(rest[:i])
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return XcodeVersion{}, fmt.Errorf("parse Xcode version %q: empty version", s)
+	}
+
+	var err error
+	v.Major, v.Minor, v.Patch, err = parseNumber(fields[0])
+	if err != nil {
+		return XcodeVersion{}, fmt.Errorf("parse Xcode version %q: %w", s, err)
+	}
+	fields = fields[1:]
+	if len(fields) == 0 {
+		return v, nil
+	}
+
+	if n, err := strconv.Atoi(fields[len(fields)-1]); err == nil {
+		v.Pre = n
+		fields = fields[:len(fields)-1]
+	}
+	v.Channel, err = ParseChannel(strings.Join(fields, " "))
+	if err != nil {
+		return XcodeVersion{}, fmt.Errorf("parse Xcode version %q: %w", s, err)
+	}
+
+	switch v.Channel {
+	case ChannelRelease, ChannelGM:
+		if v.Pre != 0 {
+			return XcodeVersion{}, fmt.Errorf("parse Xcode version %q: unexpected number of %s", s, v.Channel)
+		}
+	default:
+		if v.Pre == 0 {
+			v.Pre = 1
+		}
+	}
+
+	return v, nil
+}
+
+// parseNumber parses the dotted version number such as "13.2.1".
+func parseNumber(s string) (major, minor, patch int, err error) {
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return 0, 0, 0, fmt.Errorf("invalid version number %q", s)
+	}
+
+	nums := make([]int, 3)
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return 0, 0, 0, fmt.Errorf("invalid version number %q", s)
+		}
+		nums[i] = n
+	}
+
+	return nums[0], nums[1], nums[2], nil
+}
+
+// String returns the human readable form of v, e.g. "13.2 Beta 2 (13C5081f)".
+func (v XcodeVersion) String() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "%d.%d", v.Major, v.Minor)
+	if v.Patch != 0 {
+		fmt.Fprintf(&sb, ".%d", v.Patch)
+	}
+
+	if t := v.Channel.title(); t != "" {
+		sb.WriteString(" " + t)
+		switch v.Channel {
+		case ChannelGM:
+			// not numbered
+		case ChannelGMSeed, ChannelRC:
+			if v.Pre > 1 {
+				fmt.Fprintf(&sb, " %d", v.Pre)
+			}
+		default:
+			fmt.Fprintf(&sb, " %d", v.Pre)
+		}
+	}
+
+	if v.Build != "" {
+		fmt.Fprintf(&sb, " (%s)", v.Build)
+	}
+
+	return sb.String()
+}
+
+// Compare returns -1, 0 or +1 depending on whether v is older, the same or newer than w.
+//
+// Versions are ordered by major, minor and patch numbers, then by the channel
+// where dp < beta < gmSeed < gm == rc < release, and then by the pre-release number.
+func (v XcodeVersion) Compare(w XcodeVersion) int {
+	if c := compareInts(v.Major, w.Major); c != 0 {
+		return c
+	}
+	if c := compareInts(v.Minor, w.Minor); c != 0 {
+		return c
+	}
+	if c := compareInts(v.Patch, w.Patch); c != 0 {
+		return c
+	}
+	if c := compareInts(v.Channel.rank(), w.Channel.rank()); c != 0 {
+		return c
+	}
+
+	return compareInts(v.Pre, w.Pre)
+}
+
+// Less reports whether v is older than w.
+func (v XcodeVersion) Less(w XcodeVersion) bool {
+	return v.Compare(w) < 0
+}
+
+// IsPrerelease reports whether v is not a final release.
+func (v XcodeVersion) IsPrerelease() bool {
+	return v.Channel != ChannelRelease
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Channel returns the release channel of v.
+//
+// A missing release information is treated as the final release.
+func (v Version) Channel() Channel {
+	r := v.Release
+	switch {
+	case r == nil, r.Release:
+		return ChannelRelease
+	case r.Gm:
+		return ChannelGM
+	case r.Rc > 0:
+		return ChannelRC
+	case r.GmSeed > 0:
+		return ChannelGMSeed
+	case r.Beta > 0:
+		return ChannelBeta
+	case r.Dp > 0:
+		return ChannelDP
+	default:
+		return ChannelRelease
+	}
+}
+
+// XcodeVersion parses v into the comparable XcodeVersion.
+func (v Version) XcodeVersion() (XcodeVersion, error) {
+	var xv XcodeVersion
+
+	var err error
+	xv.Major, xv.Minor, xv.Patch, err = parseNumber(v.Number)
+	if err != nil {
+		return XcodeVersion{}, fmt.Errorf("parse version of %s: %w", v.Build, err)
+	}
+	xv.Build = v.Build
+	xv.Channel = v.Channel()
+
+	if r := v.Release; r != nil {
+		switch xv.Channel {
+		case ChannelRC:
+			xv.Pre = r.Rc
+		case ChannelGMSeed:
+			xv.Pre = r.GmSeed
+		case ChannelBeta:
+			xv.Pre = r.Beta
+		case ChannelDP:
+			xv.Pre = r.Dp
+		}
+	}
+
+	return xv, nil
+}
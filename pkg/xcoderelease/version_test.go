@@ -0,0 +1,142 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"testing"
+)
+
+func TestParseXcodeVersion(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    XcodeVersion
+		wantErr bool
+	}{
+		{in: "13.2.1", want: XcodeVersion{Major: 13, Minor: 2, Patch: 1}},
+		{in: "13", want: XcodeVersion{Major: 13}},
+		{in: "13.2 Beta 2 (13C5081f)", want: XcodeVersion{Major: 13, Minor: 2, Channel: ChannelBeta, Pre: 2, Build: "13C5081f"}},
+		{in: "13.2 beta 2", want: XcodeVersion{Major: 13, Minor: 2, Channel: ChannelBeta, Pre: 2}},
+		{in: "12.0 DP 3", want: XcodeVersion{Major: 12, Channel: ChannelDP, Pre: 3}},
+		{in: "11.0 GM Seed 2", want: XcodeVersion{Major: 11, Channel: ChannelGMSeed, Pre: 2}},
+		{in: "10.0 GM", want: XcodeVersion{Major: 10, Channel: ChannelGM}},
+		{in: "13.0 RC", want: XcodeVersion{Major: 13, Channel: ChannelRC, Pre: 1}},
+		{in: "13.0 Release Candidate 2", want: XcodeVersion{Major: 13, Channel: ChannelRC, Pre: 2}},
+		{in: "  13.1  (13A1030d)  ", want: XcodeVersion{Major: 13, Minor: 1, Build: "13A1030d"}},
+		{in: "", wantErr: true},
+		{in: "13.x", wantErr: true},
+		{in: "13.2.1.1", wantErr: true},
+		{in: "13.2 Alpha 1", wantErr: true},
+		{in: "13.2 GM 2", wantErr: true},
+		{in: "13.2 (13C100", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseXcodeVersion(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseXcodeVersion(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseXcodeVersion(%q) = %#v, want %#v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestXcodeVersionStringRoundTrip(t *testing.T) {
+	tests := []string{
+		"13.2.1 (13C100)",
+		"13.2",
+		"13.2 Beta 2 (13C5081f)",
+		"12.0 DP 3",
+		"11.0 GM Seed",
+		"11.0 GM Seed 2",
+		"10.0 GM (10A255)",
+		"13.0 RC (13A233)",
+		"13.0 RC 2",
+	}
+	for _, s := range tests {
+		v, err := ParseXcodeVersion(s)
+		if err != nil {
+			t.Errorf("ParseXcodeVersion(%q) error = %v", s, err)
+			continue
+		}
+		if got := v.String(); got != s {
+			t.Errorf("ParseXcodeVersion(%q).String() = %q", s, got)
+		}
+	}
+}
+
+func TestXcodeVersionCompare(t *testing.T) {
+	// ordered from the oldest
+	ordered := []string{
+		"12.5.1",
+		"13.0 DP 1",
+		"13.0 DP 2",
+		"13.0 Beta 1",
+		"13.0 Beta 5",
+		"13.0 GM Seed 1",
+		"13.0 GM Seed 2",
+		"13.0 RC 1",
+		"13.0 RC 2",
+		"13.0",
+		"13.0.1 Beta 1",
+		"13.0.1",
+		"13.1 Beta 1",
+		"13.1",
+		"13.10",
+		"14.0 Beta 1",
+	}
+	vs := make([]XcodeVersion, len(ordered))
+	for i, s := range ordered {
+		v, err := ParseXcodeVersion(s)
+		if err != nil {
+			t.Fatalf("ParseXcodeVersion(%q) error = %v", s, err)
+		}
+		vs[i] = v
+	}
+
+	for i := range vs {
+		for j := range vs {
+			want := compareInts(i, j)
+			if got := vs[i].Compare(vs[j]); got != want {
+				t.Errorf("%q.Compare(%q) = %d, want %d", ordered[i], ordered[j], got, want)
+			}
+			if got := vs[i].Less(vs[j]); got != (want < 0) {
+				t.Errorf("%q.Less(%q) = %v, want %v", ordered[i], ordered[j], got, want < 0)
+			}
+		}
+	}
+}
+
+func TestXcodeVersionCompareGMAndRC(t *testing.T) {
+	gm := XcodeVersion{Major: 10, Channel: ChannelGM}
+	rc := XcodeVersion{Major: 10, Channel: ChannelRC}
+	if c := gm.Compare(rc); c != 0 {
+		t.Errorf("GM.Compare(RC) = %d, want 0", c)
+	}
+
+	withBuild := XcodeVersion{Major: 10, Build: "10A255"}
+	if c := withBuild.Compare(XcodeVersion{Major: 10, Build: "10A254a"}); c != 0 {
+		t.Errorf("Compare with different builds = %d, want 0", c)
+	}
+}
+
+func TestVersionChannel(t *testing.T) {
+	tests := []struct {
+		r    *Release
+		want Channel
+	}{
+		{r: nil, want: ChannelRelease},
+		{r: &Release{Release: true}, want: ChannelRelease},
+		{r: &Release{Beta: 2}, want: ChannelBeta},
+		{r: &Release{Dp: 1}, want: ChannelDP},
+		{r: &Release{GmSeed: 1}, want: ChannelGMSeed},
+		{r: &Release{Gm: true}, want: ChannelGM},
+		{r: &Release{Rc: 1}, want: ChannelRC},
+	}
+	for _, tt := range tests {
+		if got := (Version{Release: tt.r}).Channel(); got != tt.want {
+			t.Errorf("Channel(%+v) = %v, want %v", tt.r, got, tt.want)
+		}
+	}
+}
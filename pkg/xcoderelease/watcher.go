@@ -0,0 +1,287 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	json "github.com/goccy/go-json"
+)
+
+// EventKind represents a kind of Event.
+type EventKind int
+
+const (
+	// EventNewRelease is sent when a release appears.
+	EventNewRelease EventKind = iota + 1
+
+	// EventPromoted is sent when a release moves to a later channel, e.g. from RC to the final release.
+	EventPromoted
+
+	// EventDataChanged is sent when the other fields of a release change, e.g. a checksum or a download link.
+	EventDataChanged
+
+	// EventRemoved is sent when a release disappears.
+	EventRemoved
+)
+
+// String implements fmt.Stringer.
+func (k EventKind) String() string {
+	switch k {
+	case EventNewRelease:
+		return "new release"
+	case EventPromoted:
+		return "promoted"
+	case EventDataChanged:
+		return "data changed"
+	case EventRemoved:
+		return "removed"
+	default:
+		return fmt.Sprintf("EventKind(%d)", int(k))
+	}
+}
+
+// Event is a change of the releases detected by Watcher.
+type Event struct {
+	Kind EventKind
+
+	// Release is the new release, or the removed one for EventRemoved.
+	Release *XcodeRelease
+
+	// Change is the field-level change for EventPromoted and EventDataChanged.
+	Change *ReleaseChange
+}
+
+// String returns the human readable summary of e.
+func (e Event) String() string {
+	return fmt.Sprintf("%s: %s", e.Kind, releaseTitle(e.Release))
+}
+
+// WatchState is the last-seen releases persisted by StateStore.
+type WatchState struct {
+	// UpdatedAt is the time of the poll which saw the releases.
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	// Releases is the last-seen releases.
+	Releases []*XcodeRelease `json:"releases"`
+}
+
+// StateStore persists the last-seen state of Watcher across restarts.
+type StateStore interface {
+	// Load loads the saved state. It returns nil state and nil error if nothing is saved yet.
+	Load(ctx context.Context) (*WatchState, error)
+
+	// Save saves the state.
+	Save(ctx context.Context, st *WatchState) error
+}
+
+// FileStateStore is the StateStore which saves the state to a JSON file.
+type FileStateStore struct {
+	Path string
+}
+
+var _ StateStore = (*FileStateStore)(nil)
+
+// Load implements StateStore.
+func (s *FileStateStore) Load(ctx context.Context) (*WatchState, error) {
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	st := new(WatchState)
+	if err := json.Unmarshal(data, st); err != nil {
+		return nil, fmt.Errorf("load watch state %s: %w", s.Path, err)
+	}
+
+	return st, nil
+}
+
+// Save implements StateStore.
+func (s *FileStateStore) Save(ctx context.Context, st *WatchState) error {
+	data, err := marshalJSON(st)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0o755); err != nil {
+		return err
+	}
+
+	return writeFileAtomic(s.Path, data)
+}
+
+// MemoryStateStore is the StateStore which keeps the state in memory.
+type MemoryStateStore struct {
+	mu sync.Mutex
+	st *WatchState
+}
+
+var _ StateStore = (*MemoryStateStore)(nil)
+
+// Load implements StateStore.
+func (s *MemoryStateStore) Load(ctx context.Context) (*WatchState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.st, nil
+}
+
+// Save implements StateStore.
+func (s *MemoryStateStore) Save(ctx context.Context, st *WatchState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.st = st
+	return nil
+}
+
+// Watcher polls the releases and reports their changes as Events.
+//
+// The first poll without a saved state only records the releases, so that the existing
+// releases are not announced.
+type Watcher struct {
+	// Client fetches the releases. If nil, DefaultClient is used.
+	Client *Client
+
+	// Interval is the interval between polls. If zero, one hour is used.
+	Interval time.Duration
+
+	// Jitter is the fraction of Interval randomly added or subtracted, in range [0, 1].
+	Jitter float64
+
+	// Store persists the last-seen state. If nil, the state is kept in memory.
+	Store StateStore
+
+	// OnError is called with the errors of the polls, which are otherwise ignored.
+	OnError func(error)
+
+	initOnce sync.Once
+}
+
+func (w *Watcher) init() {
+	w.initOnce.Do(func() {
+		if w.Client == nil {
+			w.Client = DefaultClient
+		}
+		if w.Interval <= 0 {
+			w.Interval = time.Hour
+		}
+		if w.Store == nil {
+			w.Store = new(MemoryStateStore)
+		}
+	})
+}
+
+// Poll fetches the releases once, and calls handle with each change since the saved state.
+//
+// The state is saved only after handle returns nil for all the changes. If handle returns an error, Poll
+// returns it without saving the state, so the same changes are delivered again by the next poll.
+func (w *Watcher) Poll(ctx context.Context, handle func(Event) error) error {
+	w.init()
+
+	xrs, err := w.Client.Releases(ctx)
+	if err != nil {
+		return fmt.Errorf("poll releases: %w", err)
+	}
+
+	prev, err := w.Store.Load(ctx)
+	if err != nil {
+		return err
+	}
+
+	var evs []Event
+	if prev != nil {
+		d, err := Diff(prev.Releases, xrs)
+		if err != nil {
+			return err
+		}
+		evs = diffEvents(d)
+	}
+
+	for _, ev := range evs {
+		if err := handle(ev); err != nil {
+			return err
+		}
+	}
+
+	if prev == nil || len(evs) > 0 {
+		if err := w.Store.Save(ctx, &WatchState{UpdatedAt: time.Now(), Releases: xrs}); err != nil {
+			return fmt.Errorf("save watch state: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// diffEvents converts d into Events.
+func diffEvents(d *DiffResult) []Event {
+	var evs []Event
+
+	for _, xr := range d.Added {
+		evs = append(evs, Event{Kind: EventNewRelease, Release: xr})
+	}
+	for _, c := range d.Changed {
+		kind := EventDataChanged
+		if c.Promoted() {
+			kind = EventPromoted
+		}
+		evs = append(evs, Event{Kind: kind, Release: c.New, Change: c})
+	}
+	for _, xr := range d.Removed {
+		evs = append(evs, Event{Kind: EventRemoved, Release: xr})
+	}
+
+	return evs
+}
+
+// Watch polls the releases until ctx is canceled, sending the changes to the returned channel.
+// The channel is closed when ctx is canceled.
+//
+// The state is saved after all the changes of a poll are received from the channel. If ctx is canceled
+// before that, the undelivered changes are sent again by the next Watch with the same Store.
+func (w *Watcher) Watch(ctx context.Context) <-chan Event {
+	w.init()
+
+	ch := make(chan Event)
+	go func() {
+		defer close(ch)
+
+		for {
+			err := w.Poll(ctx, func(ev Event) error {
+				select {
+				case ch <- ev:
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			})
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil && w.OnError != nil {
+				w.OnError(err)
+			}
+
+			t := time.NewTimer(jitter(w.Interval, w.Jitter))
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return
+			case <-t.C:
+			}
+		}
+	}()
+
+	return ch
+}
@@ -0,0 +1,123 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWatcherPollSavesAfterDelivery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+	writeReleases := func(xrs []*XcodeRelease) {
+		t.Helper()
+		data, err := Marshal(xrs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c, err := NewClient(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := new(MemoryStateStore)
+	w := &Watcher{Client: c, Store: store}
+	ctx := context.Background()
+
+	var got []Event
+	handle := func(ev Event) error {
+		got = append(got, ev)
+		return nil
+	}
+
+	writeReleases(testReleases(t, "13.2 (13C90)"))
+	if err := w.Poll(ctx, handle); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("first Poll() delivered %v, want nothing", got)
+	}
+
+	writeReleases(testReleases(t, "13.2.1 (13C100)", "13.2 (13C90)"))
+	errDeliver := errors.New("deliver")
+	if err := w.Poll(ctx, func(Event) error { return errDeliver }); !errors.Is(err, errDeliver) {
+		t.Fatalf("Poll() error = %v, want %v", err, errDeliver)
+	}
+	if st, _ := store.Load(ctx); len(st.Releases) != 1 {
+		t.Fatalf("state saved after the failed delivery: %d releases", len(st.Releases))
+	}
+
+	if err := w.Poll(ctx, handle); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Kind != EventNewRelease || got[0].Release.Version.Build != "13C100" {
+		t.Fatalf("Poll() delivered %v, want the new 13C100", got)
+	}
+	if st, _ := store.Load(ctx); len(st.Releases) != 2 {
+		t.Fatalf("state has %d releases after the delivery, want 2", len(st.Releases))
+	}
+
+	got = nil
+	if err := w.Poll(ctx, handle); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("Poll() delivered %v again", got)
+	}
+}
+
+func TestWatcherPollPromoted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+	writeReleases := func(xrs []*XcodeRelease) {
+		t.Helper()
+		data, err := Marshal(xrs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c, err := NewClient(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := &Watcher{Client: c, Store: new(MemoryStateStore)}
+	ctx := context.Background()
+
+	var got []Event
+	handle := func(ev Event) error {
+		got = append(got, ev)
+		return nil
+	}
+
+	writeReleases(testReleases(t, "13.0 RC (13A233)"))
+	if err := w.Poll(ctx, handle); err != nil {
+		t.Fatal(err)
+	}
+
+	// the final release is added next to the RC it was built from
+	writeReleases(testReleases(t, "13.0 (13A233)", "13.0 RC (13A233)"))
+	if err := w.Poll(ctx, handle); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("Poll() delivered %v, want a single event", got)
+	}
+	ev := got[0]
+	if ev.Kind != EventPromoted {
+		t.Errorf("Kind = %v, want %v", ev.Kind, EventPromoted)
+	}
+	if ev.Release.Version.Channel() != ChannelRelease || ev.Change == nil || ev.Change.Old.Version.Channel() != ChannelRC {
+		t.Errorf("Poll() delivered %+v, want 13A233 promoted from the RC", ev)
+	}
+}
@@ -7,9 +7,8 @@ package xcoderelease
 import (
 	"context"
 	"fmt"
-	"io"
-	"net/http"
 	"net/url"
+	"time"
 
 	json "github.com/goccy/go-json"
 )
@@ -20,34 +19,14 @@ var dataURI = &url.URL{
 	Path:   "data.json",
 }
 
-// DownloadJSON downoads xcodereleases data.json.
+// DownloadJSON downloads xcodereleases data.json using DefaultClient.
 func DownloadJSON(ctx context.Context) ([]byte, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, dataURI.String(), nil)
-	if err != nil {
-		return nil, err
-	}
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	data, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	return data, nil
+	return DefaultClient.DownloadJSON(ctx)
 }
 
-// Unmarshal parses the xcodereleases.com JSON-encoded data and returns the new Xcoderelease.
-func Unmarshal(data []byte) (xrs []*XcodeRelease, err error) {
-	if err := json.UnmarshalNoEscape(data, &xrs); err != nil {
-		return nil, fmt.Errorf("unmarshal data: %w", err)
-	}
-
-	return xrs, nil
+// Unmarshal parses the xcodereleases.com JSON-encoded data and returns the new XcodeRelease using DefaultClient.
+func Unmarshal(data []byte) ([]*XcodeRelease, error) {
+	return DefaultClient.Unmarshal(data)
 }
 
 // XcodeRelease represents a Xcode release information.
@@ -60,42 +39,38 @@ type XcodeRelease struct {
 	Checksums Checksum   `json:"checksums"`
 	Date      Date       `json:"date"`
 	Links     Link       `json:"links"`
+	// Extra holds the unknown fields kept by UnmarshalLenient.
+	Extra *Extra `json:"-"`
 }
 
-// SDKs represents a Apple each OS sdks.
-type SDKs struct {
-	MacOS   []MacOS   `json:"macOS"`
-	IOS     []IOS     `json:"iOS"`
-	TvOS    []TvOS    `json:"tvOS"`
-	WatchOS []WatchOS `json:"watchOS"`
+// Extra holds the fields of a JSON object which are not known to this package.
+//
+// The structs hold it by pointer so that they stay comparable.
+type Extra struct {
+	// Fields is the unknown fields keyed by the field name.
+	Fields map[string]json.RawMessage
 }
 
-// MacOS represents a details of macOS sdk.
-type MacOS struct {
-	Build   string `json:"build"`
-	Number  string `json:"number"`
-	Release bool   `json:"release"`
-}
-
-// IOS represents a details of iOS sdk.
-type IOS struct {
-	Build   string `json:"build"`
-	Number  string `json:"number"`
-	Release bool   `json:"release"`
-}
+// SDKs represents a Apple each OS sdks.
+//
+// The SDKs of the platforms not known to this package are kept in Other.
+type SDKs struct {
+	MacOS   []SDK `json:"macOS"`
+	IOS     []SDK `json:"iOS"`
+	TvOS    []SDK `json:"tvOS"`
+	WatchOS []SDK `json:"watchOS"`
 
-// TvOS represents a details of TvOS sdk.
-type TvOS struct {
-	Build   string `json:"build"`
-	Number  string `json:"number"`
-	Release bool   `json:"release"`
+	// Other holds the SDKs of the unknown platforms keyed by the platform name.
+	Other map[Platform][]SDK `json:"-"`
 }
 
-// WatchOS represents a details of WatchOS sdk.
-type WatchOS struct {
+// SDK represents a details of the platform sdk.
+type SDK struct {
 	Build   string `json:"build"`
 	Number  string `json:"number"`
 	Release bool   `json:"release"`
+	// Extra holds the unknown fields kept by UnmarshalLenient.
+	Extra *Extra `json:"-"`
 }
 
 // Version represents a release version.
@@ -103,6 +78,8 @@ type Version struct {
 	Build   string   `json:"build"`
 	Number  string   `json:"number"`
 	Release *Release `json:"release,omitempty"`
+	// Extra holds the unknown fields kept by UnmarshalLenient.
+	Extra *Extra `json:"-"`
 }
 
 // Release represents a release of version.
@@ -113,6 +90,8 @@ type Release struct {
 	GmSeed  int  `json:"gmSeed,omitempty"`
 	Rc      int  `json:"rc,omitempty"`
 	Release bool `json:"release"`
+	// Extra holds the unknown fields kept by UnmarshalLenient.
+	Extra *Extra `json:"-"`
 }
 
 // Compilers represents a compilers information of Xcode.
@@ -122,6 +101,8 @@ type Compilers struct {
 	Llvm    []LLVMCompiler    `json:"llvm,omitempty"`
 	LlvmGcc []LLVMGCCCompiler `json:"llvm_gcc,omitempty"`
 	Swift   []SwiftCompiler   `json:"swift"`
+	// Extra holds the unknown fields kept by UnmarshalLenient.
+	Extra *Extra `json:"-"`
 }
 
 // ClangCompiler represents a clang compiler information of Xcode.
@@ -129,6 +110,8 @@ type ClangCompiler struct {
 	Build   string `json:"build"`
 	Number  string `json:"number"`
 	Release bool   `json:"release"`
+	// Extra holds the unknown fields kept by UnmarshalLenient.
+	Extra *Extra `json:"-"`
 }
 
 // ClangCompiler represents a GCC compiler information of Xcode.
@@ -136,12 +119,16 @@ type GCCCompiler struct {
 	Build   string `json:"build"`
 	Number  string `json:"number"`
 	Release bool   `json:"release"`
+	// Extra holds the unknown fields kept by UnmarshalLenient.
+	Extra *Extra `json:"-"`
 }
 
 // ClangCompiler represents a LLVM compiler information of Xcode.
 type LLVMCompiler struct {
 	Number  string `json:"number"`
 	Release bool   `json:"release"`
+	// Extra holds the unknown fields kept by UnmarshalLenient.
+	Extra *Extra `json:"-"`
 }
 
 // LLVMGCCCompiler represents a LLVM GCC compiler information of Xcode.
@@ -149,6 +136,8 @@ type LLVMGCCCompiler struct {
 	Build   string `json:"build"`
 	Number  string `json:"number"`
 	Release bool   `json:"release"`
+	// Extra holds the unknown fields kept by UnmarshalLenient.
+	Extra *Extra `json:"-"`
 }
 
 // SwiftCompiler represents a swift compiler information of Xcode.
@@ -156,11 +145,15 @@ type SwiftCompiler struct {
 	Build   string `json:"build"`
 	Number  string `json:"number"`
 	Release bool   `json:"release"`
+	// Extra holds the unknown fields kept by UnmarshalLenient.
+	Extra *Extra `json:"-"`
 }
 
 // Checksum checksum of Xcode xip tarball.
 type Checksum struct {
 	Sha1 string `json:"sha1,omitempty"`
+	// Extra holds the unknown fields kept by UnmarshalLenient.
+	Extra *Extra `json:"-"`
 }
 
 // Date represents a release date of Xcode release.
@@ -168,15 +161,36 @@ type Date struct {
 	Day   int `json:"day"`
 	Month int `json:"month"`
 	Year  int `json:"year"`
+	// Extra holds the unknown fields kept by UnmarshalLenient.
+	Extra *Extra `json:"-"`
+}
+
+// Time returns the release date as the midnight in UTC.
+func (d Date) Time() time.Time {
+	return time.Date(d.Year, time.Month(d.Month), d.Day, 0, 0, 0, 0, time.UTC)
+}
+
+// Before reports whether d is before e.
+func (d Date) Before(e Date) bool {
+	return d.Time().Before(e.Time())
+}
+
+// String returns d in the YYYY-MM-DD format.
+func (d Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
 }
 
 // Link represents a link of Xcode release.
 type Link struct {
 	Download URL `json:"download"`
 	Notes    URL `json:"notes"`
+	// Extra holds the unknown fields kept by UnmarshalLenient.
+	Extra *Extra `json:"-"`
 }
 
 // URL represents a url of Xcode release.
 type URL struct {
 	URL string `json:"url"`
+	// Extra holds the unknown fields kept by UnmarshalLenient.
+	Extra *Extra `json:"-"`
 }
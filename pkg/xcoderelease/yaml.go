@@ -0,0 +1,206 @@
+// Copyright 2021 The Go Darwin Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xcoderelease
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	json "github.com/goccy/go-json"
+)
+
+// EncodeYAML writes xrs to w as YAML, with the same structure and field order as Marshal.
+func EncodeYAML(w io.Writer, xrs []*XcodeRelease) error {
+	data, err := Marshal(xrs)
+	if err != nil {
+		return err
+	}
+
+	return writeYAML(w, data)
+}
+
+// MarshalYAML returns the YAML encoding of v, with the same structure and field order as its JSON encoding.
+func MarshalYAML(v interface{}) ([]byte, error) {
+	data, err := marshalJSON(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := writeYAML(&buf, data); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeYAML writes the JSON document data to w as YAML, keeping the order of the object keys.
+func writeYAML(w io.Writer, data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	n, err := readYAMLNode(dec)
+	if err != nil {
+		return fmt.Errorf("encode YAML: %w", err)
+	}
+
+	var buf bytes.Buffer
+	switch {
+	case n.isEmpty() || n.kind == yamlScalar:
+		buf.WriteString(n.flow() + "\n")
+	default:
+		n.write(&buf, 0)
+	}
+
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+type yamlKind int
+
+const (
+	yamlScalar yamlKind = iota
+	yamlMapping
+	yamlSequence
+)
+
+// yamlNode is an ordered JSON value tree.
+type yamlNode struct {
+	kind   yamlKind
+	scalar string // YAML representation of a scalar
+	keys   []string
+	values []*yamlNode
+}
+
+func readYAMLNode(dec *json.Decoder) (*yamlNode, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			n := &yamlNode{kind: yamlMapping}
+			for dec.More() {
+				ktok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key, ok := ktok.(string)
+				if !ok {
+					return nil, fmt.Errorf("unexpected object key %v", ktok)
+				}
+				v, err := readYAMLNode(dec)
+				if err != nil {
+					return nil, err
+				}
+				n.keys = append(n.keys, key)
+				n.values = append(n.values, v)
+			}
+			if _, err := dec.Token(); err != nil { // '}'
+				return nil, err
+			}
+			return n, nil
+		case '[':
+			n := &yamlNode{kind: yamlSequence}
+			for dec.More() {
+				v, err := readYAMLNode(dec)
+				if err != nil {
+					return nil, err
+				}
+				n.values = append(n.values, v)
+			}
+			if _, err := dec.Token(); err != nil { // ']'
+				return nil, err
+			}
+			return n, nil
+		default:
+			return nil, fmt.Errorf("unexpected delimiter %v", t)
+		}
+	case string:
+		return &yamlNode{scalar: yamlString(t)}, nil
+	case json.Number:
+		return &yamlNode{scalar: t.String()}, nil
+	case bool:
+		return &yamlNode{scalar: fmt.Sprint(t)}, nil
+	case nil:
+		return &yamlNode{scalar: "null"}, nil
+	default:
+		return &yamlNode{scalar: fmt.Sprint(t)}, nil
+	}
+}
+
+func (n *yamlNode) isEmpty() bool {
+	return n.kind != yamlScalar && len(n.values) == 0
+}
+
+// flow returns the inline representation of the scalar or the empty collection n.
+func (n *yamlNode) flow() string {
+	switch n.kind {
+	case yamlMapping:
+		return "{}"
+	case yamlSequence:
+		return "[]"
+	default:
+		return n.scalar
+	}
+}
+
+// write writes the non-empty collection n in block style at the indent level.
+func (n *yamlNode) write(buf *bytes.Buffer, indent int) {
+	pad := strings.Repeat("  ", indent)
+
+	switch n.kind {
+	case yamlMapping:
+		for i, key := range n.keys {
+			v := n.values[i]
+			buf.WriteString(pad + yamlString(key) + ":")
+			switch {
+			case v.kind == yamlScalar || v.isEmpty():
+				buf.WriteString(" " + v.flow() + "\n")
+			case v.kind == yamlSequence:
+				buf.WriteString("\n")
+				v.write(buf, indent) // sequences are not indented under the mapping key
+			default:
+				buf.WriteString("\n")
+				v.write(buf, indent+1)
+			}
+		}
+
+	case yamlSequence:
+		for _, v := range n.values {
+			switch {
+			case v.kind == yamlScalar || v.isEmpty():
+				buf.WriteString(pad + "- " + v.flow() + "\n")
+			default:
+				// write the first line of the item after the dash
+				var item bytes.Buffer
+				v.write(&item, indent+1)
+				buf.WriteString(pad + "- " + strings.TrimPrefix(item.String(), pad+"  "))
+			}
+		}
+	}
+}
+
+var yamlPlain = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_ ./()+-]*$`)
+
+// yamlString returns s as a YAML plain scalar if it is unambiguous, otherwise as a double-quoted scalar.
+func yamlString(s string) string {
+	if yamlPlain.MatchString(s) && !strings.HasSuffix(s, " ") && !strings.Contains(s, " #") {
+		switch strings.ToLower(s) {
+		case "true", "false", "yes", "no", "on", "off", "y", "n", "null", "nan", "inf":
+			// needs quoting
+		default:
+			return s
+		}
+	}
+
+	q, _ := marshalJSON(s)
+	return string(q)
+}